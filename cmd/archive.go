@@ -23,28 +23,50 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
 	"github.com/spf13/cobra"
 )
 
 // checkCmd represents the check command
 var archiveCmd = &cobra.Command{
-	Use:   "archive",
+	Use:   "archive [query]",
 	Aliases: []string{"a"},
 	Short: "Display archived items",
 	DisableFlagsInUseLine: true,
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Long: `
+Lists the items on the archive board, or only those matching a query.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+Examples:
+
+   cb archive
+   cb archive --group-by day --sort created
+   cb archive is:cancelled 'completed:>=2026-01-01'
+   cb archive --output json
+`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("archive called")
+		query := itemQuery(args)
+		factory := func() []view.Section {
+			return []view.Section{boardSection(data.ArchiveBoard, func(it *data.Item) bool {
+				return query.Match(it, itemstore.BoardsOf(it.Id))
+			})}
+		}
+		if groupBy != "" {
+			factory = groupedSections(matching(query, func() []*data.Item {
+				return itemstore.ItemsInBoards(data.ArchiveBoard)
+			}), view.GroupNone, view.ById)
+		}
+		newRenderer().PrintSections(factory)
+		if textOutput() {
+			fmt.Println()
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(archiveCmd)
+	addListingFlags(archiveCmd)
+	archiveCmd.Long += queryHelp
 
 	// Here you will define your flags and configuration settings.
 
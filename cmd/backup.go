@@ -0,0 +1,211 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// backupStamp names backup files so that they sort in the order they were taken.
+const backupStamp = "20060102T150405.000000000Z"
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "List and restore backups of the collabbook",
+	DisableFlagsInUseLine: true,
+	Long: `
+Every time a command changes the collabbook, the previous version is saved in
+.collabbook.d/backups alongside it. The number of backups kept is set by the
+backups.keep option in the config file, and defaults to 10. Setting it to 0
+disables backups.
+
+Examples:
+
+   cb backup list
+   cb backup restore 1
+`,
+	// PersistentPreRun locates the book without loading it, so that damaged books can still be restored.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		cbPath = findCollabbook()
+	},
+	// PersistentPostRun acts as a noop; restore saves the book itself.
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+}
+
+// backupListCmd represents the backup list command
+var backupListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"l"},
+	Short:   "List backups, newest first",
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		backups, err := listBackups(cbPath)
+		if err != nil {
+			view.Failure(`:-O`, "Could not list backups because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		if len(backups) == 0 {
+			view.Success(`:-|`, "No backups found")
+			fmt.Println()
+			return
+		}
+
+		fmt.Println()
+		for i, backup := range backups {
+			count := "unreadable"
+			if store, err := loadCollabbook(backup.path); err == nil {
+				count = strconv.Itoa(store.Len()) + " items"
+			}
+			fmt.Printf("  %4d. %s  %s\n", i+1, backup.taken.Local().Format("2006-01-02 15:04:05"), count)
+		}
+	},
+}
+
+// backupRestoreCmd represents the backup restore command
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <n>",
+	Short: "Restore a backup",
+	DisableFlagsInUseLine: true,
+	Long: `
+Replaces the collabbook with the nth most recent backup, as numbered by
+'cb backup list'. The current book is itself backed up first, so a restore can
+be undone by restoring again.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		backups, err := listBackups(cbPath)
+		if err != nil {
+			view.Failure(`:-O`, "Could not list backups because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > len(backups) {
+			view.Failure(`:-\`, args[0]+" does not refer to any backup")
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		backup := backups[n-1]
+		store, err := loadCollabbook(backup.path)
+		if err != nil {
+			view.Failure(`:-(`, "Backup "+args[0]+" cannot be read:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		if err := rebaseEvents(cbPath, store); err != nil {
+			view.Failure(`:-O`, "Could not update the event log because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		if err := saveCollabbook(cbPath, store); err != nil {
+			view.Failure(`:-O`, "Could not write file because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		view.Success(`:-)`, "Restored backup from "+backup.taken.Local().Format("2006-01-02 15:04:05"))
+		fmt.Println()
+	},
+}
+
+type backup struct {
+	path  string
+	taken time.Time
+}
+
+// bookDir returns the path of elem within the .collabbook.d directory kept alongside the book at path.
+func bookDir(path string, elem ...string) string {
+	return filepath.Join(append([]string{filepath.Dir(path), ".collabbook.d"}, elem...)...)
+}
+
+// listBackups returns the backups of the book at path, newest first.
+func listBackups(path string) ([]backup, error) {
+	infos, err := ioutil.ReadDir(bookDir(path, "backups"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []backup
+	for _, info := range infos {
+		taken, err := time.Parse(backupStamp, strings.TrimSuffix(info.Name(), ".collabbook"))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		result = append(result, backup{bookDir(path, "backups", info.Name()), taken})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].taken.After(result[j].taken) })
+	return result, nil
+}
+
+// keepAllBackups, given as the number of backups to keep, takes a backup without discarding any older ones.
+const keepAllBackups = -1
+
+// writeBackup saves text as the newest backup of the book at path, discarding the oldest backups beyond keep unless
+// keep is keepAllBackups.
+func writeBackup(path string, text []byte, keep int) error {
+	dir := bookDir(path, "backups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := time.Now().UTC().Format(backupStamp) + ".collabbook"
+	if err := ioutil.WriteFile(filepath.Join(dir, name), text, 0644); err != nil {
+		return err
+	}
+	if keep == keepAllBackups {
+		return nil
+	}
+
+	backups, err := listBackups(path)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i].path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+
+	viper.SetDefault("backups.keep", 10)
+}
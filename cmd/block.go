@@ -0,0 +1,50 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/spf13/cobra"
+)
+
+// blockCmd represents the block command
+var blockCmd = &cobra.Command{
+	Use:   "block",
+	Short: "Mark task as blocked",
+	DisableFlagsInUseLine: true,
+	Long: `
+Marks one or more open tasks as blocked, waiting on something else. Use
+cb begin to pick them up again once they are unblocked. Items may be referred
+to either by their display number or by their unique id.
+
+Examples:
+
+   cb block 3
+   cb block 3 4 01HB8ZK3M4Q2V6W8X9Y0Z1A2B3
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  setTaskStates(data.Blocked, "Blocked"),
+}
+
+func init() {
+	rootCmd.AddCommand(blockCmd)
+}
@@ -0,0 +1,104 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+)
+
+var burndownFrom, burndownTo string
+
+// burndownCmd represents the burndown command
+var burndownCmd = &cobra.Command{
+	Use:   "burndown",
+	Short: "Chart the open tasks left on a board",
+	DisableFlagsInUseLine: true,
+	Long: `
+Charts how many tasks on a board were still open at the end of each day,
+against an ideal line falling steadily to zero on the last day. The chart is
+worked out from when each task was created and completed.
+
+The chart starts on the day the board's oldest task was created and ends today,
+unless --from or --to are given as dates in the form 2006-01-02.
+
+Examples:
+
+   cb burndown #sprint-12
+   cb burndown #sprint-12 --from 2026-10-01 --to 2026-10-14
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		board := args[0]
+		if itemstore.IdsInBoard(board) == nil {
+			view.Failure(`:-\`, "No board named "+board)
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		from := itemstore.Earliest(board)
+		if burndownFrom != "" {
+			from = parseDate("--from", burndownFrom)
+		}
+		to := time.Now()
+		if burndownTo != "" {
+			to = parseDate("--to", burndownTo)
+		}
+		if from.IsZero() {
+			view.Failure(`:-\`, "No tasks on "+board)
+			fmt.Println()
+			os.Exit(1)
+		}
+		from = from.Local()
+		if to.Before(from) {
+			view.Failure(`:-\`, "The chart must end after it starts")
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
+		newRenderer().PrintBurndown(board, from, itemstore.Burndown(board, from, to))
+		fmt.Println()
+	},
+}
+
+// parseDate reads a date given to the named flag, in the local time zone.
+func parseDate(flag, value string) time.Time {
+	result, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		view.Failure(`:-\`, flag+" must be a date like 2006-01-02, not "+value)
+		fmt.Println()
+		os.Exit(1)
+	}
+	return result
+}
+
+func init() {
+	rootCmd.AddCommand(burndownCmd)
+
+	burndownCmd.Flags().StringVar(&burndownFrom, "from", "", "first day of the chart, like 2006-01-02")
+	burndownCmd.Flags().StringVar(&burndownTo, "to", "", "last day of the chart, like 2006-01-02")
+}
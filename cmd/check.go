@@ -22,7 +22,9 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
 
+	"github.com/kalexmills/collabbook-go/view"
 	"github.com/spf13/cobra"
 )
 
@@ -32,14 +34,30 @@ var checkCmd = &cobra.Command{
 	Aliases: []string{"c"},
 	Short:   "Check/uncheck task",
 	DisableFlagsInUseLine: true,
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Long: `
+Toggles the completion of one or more tasks. Items may be referred to either by
+their display number or by their unique id.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+Examples:
+
+   cb check 3
+   cb check 3 01HB8ZK3M4Q2V6W8X9Y0Z1A2B3
+`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("check called")
+		for _, item := range lookupItems(args) {
+			item, err := itemstore.ToggleTaskIsComplete(item.Id)
+			if err != nil {
+				view.Failure(`:-\`, err.Error())
+				continue
+			}
+			if item.IsComplete() {
+				view.Success(`:-)`, "Checked task: "+strconv.FormatUint(item.Id, 10))
+			} else {
+				view.Success(`:-)`, "Unchecked task: "+strconv.FormatUint(item.Id, 10))
+			}
+		}
+		fmt.Println()
 	},
 }
 
@@ -22,7 +22,9 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
 
+	"github.com/kalexmills/collabbook-go/view"
 	"github.com/spf13/cobra"
 )
 
@@ -31,14 +33,17 @@ var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete item",
 	DisableFlagsInUseLine: true,
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Long: `
+Permanently deletes one or more items from every board. Items may be referred
+to either by their display number or by their unique id.
+`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("delete called")
+		for _, item := range lookupItems(args) {
+			itemstore.DeleteItem(item.Id)
+			view.Success(`:-)`, "Deleted item: "+strconv.FormatUint(item.Id, 10))
+		}
+		fmt.Println()
 	},
 }
 
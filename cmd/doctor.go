@@ -0,0 +1,128 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var doctorFix bool
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the collabbook for problems",
+	DisableFlagsInUseLine: true,
+	Long: `
+Reads the nearest .collabbook file and reports every problem found in it.
+
+Lines which cannot be read are reported with their line number, field and text,
+so that they can be repaired by hand. Books which can be read are then checked
+for inconsistencies: boards referring to missing items, empty items, items on
+no board at all, and items sharing a unique id after a manual merge.
+
+With --fix, inconsistencies are repaired and the book is saved, backing up the
+previous version first (see 'cb backup') even when backups.keep is 0. Orphaned
+items are moved to the default board, missing items are removed from their
+boards, and items sharing a unique id are merged if they are identical or given
+a new id otherwise. Lines which cannot be read are never repaired automatically.
+
+Examples:
+
+   cb doctor
+   cb doctor --fix
+`,
+	// PersistentPreRun locates the book without loading it, so that damaged books can still be examined.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		cbPath = findCollabbook()
+	},
+	// PersistentPostRun acts as a noop so that a damaged book is never overwritten.
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := loadCollabbook(cbPath)
+		errs, ok := err.(data.ParseErrors)
+		if err != nil && !ok {
+			view.Failure(`:-O`, "Could not read "+cbPath+" because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		if len(errs) > 0 {
+			view.Failure(`:-(`, "Found "+strconv.Itoa(len(errs))+" unreadable line(s) in "+cbPath)
+			for _, err := range errs {
+				view.Detail(err.Error())
+			}
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		problems := store.Check()
+		if len(problems) == 0 {
+			view.Success(`:-)`, "No problems found in "+cbPath)
+			fmt.Println()
+			return
+		}
+		if !doctorFix {
+			view.Failure(`:-(`, "Found "+strconv.Itoa(len(problems))+" problem(s) in "+cbPath)
+			for _, problem := range problems {
+				view.Detail(problem.String())
+			}
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		problems = store.Repair()
+		if err := rebaseEvents(cbPath, store); err != nil {
+			view.Failure(`:-O`, "Could not update the event log because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		// The book is always backed up before it is repaired, even when backups are otherwise disabled, in which case
+		// the backups already taken are left alone.
+		keep := viper.GetInt("backups.keep")
+		if keep < 1 {
+			keep = keepAllBackups
+		}
+		if err := saveCollabbookKeeping(cbPath, store, keep); err != nil {
+			view.Failure(`:-O`, "Could not write file because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		view.Success(`:-)`, "Fixed "+strconv.Itoa(len(problems))+" problem(s) in "+cbPath)
+		for _, problem := range problems {
+			view.Detail(problem.String())
+		}
+		fmt.Println()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "repair any problems found, after backing up the book")
+}
@@ -22,24 +22,42 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
+	"github.com/kalexmills/collabbook-go/view"
 	"github.com/spf13/cobra"
 )
 
 // editCmd represents the edit command
 var editCmd = &cobra.Command{
-	Use:     "edit",
+	Use:     "edit <item> <description>",
 	Aliases: []string{"e"},
 	Short:   "Edit item description",
 	DisableFlagsInUseLine: true,
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Long: `
+Replaces the description of an item, referred to either by its display number
+or by its unique id.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+Examples:
+
+   cb edit 3 A better description
+   cb edit 01HB8ZK3M4Q2V6W8X9Y0Z1A2B3 A better description
+`,
+	Args: cobra.MinimumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("edit called")
+		item := lookupItems(args[:1])[0]
+		desc := strings.Join(args[1:], " ")
+		if len(strings.TrimSpace(desc)) == 0 {
+			view.Failure(`:-\`, "No description given for item "+strconv.FormatUint(item.Id, 10))
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		itemstore.EditItem(item.Id, desc)
+		view.Success(`:-)`, "Updated description of item "+strconv.FormatUint(item.Id, 10))
+		fmt.Println()
 	},
 }
 
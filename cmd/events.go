@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/spf13/viper"
+)
+
+// In event-sourced mode, every change made to a book is appended to .collabbook.d/events, one JSON event per line,
+// and the book is derived by replaying those events over the newest snapshot in .collabbook.d/snapshots. Snapshots
+// are named for the number of events they include. The .collabbook file itself is still written after every command,
+// but only as a convenient view of the current state.
+
+// eventLogEnabled reports whether books are kept in event-sourced mode.
+func eventLogEnabled() bool {
+	return viper.GetBool("eventlog.enabled")
+}
+
+// eventAuthor names the person making changes, as recorded in the event log.
+func eventAuthor() string {
+	if author := viper.GetString("author"); author != "" {
+		return author
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// loggedEvents is the number of events in the log of the open book, counted when the book is replayed and kept up to
+// date by recordEvents so that the log need not be read again on every save. It is -1 until the log has been counted.
+var loggedEvents = -1
+
+// eventLogError reports that the event log of a book, rather than the book itself, could not be read.
+type eventLogError struct {
+	err error
+}
+
+func (err *eventLogError) Error() string {
+	return err.err.Error()
+}
+
+// openCollabbook loads the book at path, deriving it from the event log in event-sourced mode.
+func openCollabbook(path string) (*data.Repo, error) {
+	if eventLogEnabled() {
+		return replayEvents(path)
+	}
+	return loadCollabbook(path)
+}
+
+// readEvents returns every event in the log of the book at path, in the order they were recorded.
+func readEvents(path string) ([]data.Event, error) {
+	file, err := os.Open(bookDir(path, "events"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var result []data.Event
+	s := bufio.NewScanner(file)
+	s.Buffer(nil, 1<<20)
+	line := 0
+	for s.Scan() {
+		line += 1
+		var event data.Event
+		if err := json.Unmarshal(s.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("event log line %d: %s", line, err)
+		}
+		result = append(result, event)
+	}
+	return result, s.Err()
+}
+
+// recordEvents appends changes to the event log of the book at path, snapshotting store once enough events have been
+// recorded since the last snapshot.
+func recordEvents(path string, store *data.Repo, changes []data.Change) error {
+	if loggedEvents < 0 {
+		events, err := readEvents(path)
+		if err != nil {
+			return err
+		}
+		loggedEvents = len(events)
+	}
+	if err := os.MkdirAll(bookDir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(bookDir(path, "events"), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(file)
+	now, author := time.Now().UTC(), eventAuthor()
+	for _, change := range changes {
+		if err := enc.Encode(data.Event{Time: now, Author: author, Change: change}); err != nil {
+			file.Close()
+			return err
+		}
+		loggedEvents += 1
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	_, included, err := latestSnapshot(path)
+	if err != nil {
+		return err
+	}
+	if loggedEvents-included >= viper.GetInt("eventlog.snapshot-every") {
+		return writeSnapshot(path, store, loggedEvents)
+	}
+	return nil
+}
+
+// rebaseEvents makes store the state of the book at path as of the latest event, for use after the book has been
+// replaced by something other than a recorded change, such as a repair or a restored backup.
+func rebaseEvents(path string, store *data.Repo) error {
+	if !eventLogEnabled() {
+		return nil
+	}
+	events, err := readEvents(path)
+	if err != nil {
+		return err
+	}
+	return writeSnapshot(path, store, len(events))
+}
+
+// replayEvents derives the book at path from its newest snapshot and the events recorded since. The first time a
+// book is opened in event-sourced mode, its .collabbook file becomes the initial snapshot.
+func replayEvents(path string) (*data.Repo, error) {
+	events, err := readEvents(path)
+	if err != nil {
+		return nil, &eventLogError{err}
+	}
+	loggedEvents = len(events)
+	snapshot, included, err := latestSnapshot(path)
+	if err != nil {
+		return nil, &eventLogError{err}
+	}
+	if snapshot == "" {
+		store, err := loadCollabbook(path)
+		if err != nil {
+			return nil, err
+		}
+		return store, writeSnapshot(path, store, len(events))
+	}
+	if included > len(events) {
+		return nil, &eventLogError{fmt.Errorf("snapshot includes %d events, but only %d were recorded", included,
+			len(events))}
+	}
+
+	store, err := loadCollabbook(snapshot)
+	if err != nil {
+		return nil, &eventLogError{fmt.Errorf("snapshot %s: %v", snapshot, err)}
+	}
+	for _, event := range events[included:] {
+		store.Apply(event.Change)
+	}
+	return store, nil
+}
+
+// latestSnapshot returns the path of the newest snapshot of the book at path and the number of events it includes.
+// The path is empty if there are no snapshots.
+func latestSnapshot(path string) (string, int, error) {
+	infos, err := ioutil.ReadDir(bookDir(path, "snapshots"))
+	if os.IsNotExist(err) {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	result, included := "", -1
+	for _, info := range infos {
+		n, err := strconv.Atoi(strings.TrimSuffix(info.Name(), ".collabbook"))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if n > included {
+			result, included = bookDir(path, "snapshots", info.Name()), n
+		}
+	}
+	if result == "" {
+		return "", 0, nil
+	}
+	return result, included, nil
+}
+
+// writeSnapshot saves store as the snapshot of the book at path which includes its first n events, discarding older
+// snapshots.
+func writeSnapshot(path string, store *data.Repo, n int) error {
+	text, err := store.MarshalText()
+	if err != nil {
+		return err
+	}
+	dir := bookDir(path, "snapshots")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := strconv.Itoa(n) + ".collabbook"
+	tmp := filepath.Join(dir, name+".tmp")
+	if err := ioutil.WriteFile(tmp, text, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+		return err
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if info.Name() != name && strings.HasSuffix(info.Name(), ".collabbook") {
+			os.Remove(filepath.Join(dir, info.Name()))
+		}
+	}
+	return nil
+}
+
+func init() {
+	viper.SetDefault("eventlog.snapshot-every", 100)
+}
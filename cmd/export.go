@@ -0,0 +1,110 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+var exportFile string
+var exportDetails bool
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [boards...]",
+	Short: "Export boards as Markdown",
+	DisableFlagsInUseLine: true,
+	Long: `
+Writes boards as GitHub-flavored Markdown, ready to paste into a pull request
+or wiki page. Each board gets a heading, tasks become a task list of - [ ] and
+- [x] entries, notes plain bullets, and starred items are bold. Without
+boards, every board except the archive is exported.
+
+With --details, each item is followed by its number, its state when the
+checkbox does not show it, and when it was created and completed.
+
+The Markdown is written to the terminal, or with -o to a file.
+
+Examples:
+
+   cb export
+   cb export #sprint-12 #release --details
+   cb export --format markdown -o STATUS.md
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if exportFormat != "markdown" {
+			view.Failure(`:-\`, fmt.Sprintf("unknown export format %q; expected markdown", exportFormat))
+			fmt.Println()
+			os.Exit(1)
+		}
+		for _, board := range args {
+			if itemstore.IdsInBoard(board) == nil {
+				view.Failure(`:-\`, "No board named "+board)
+				fmt.Println()
+				os.Exit(1)
+			}
+		}
+
+		sections := boardSections(nil)
+		if len(args) > 0 {
+			sections = make([]view.Section, len(args))
+			for i, board := range args {
+				sections[i] = boardSection(board, nil)
+			}
+		}
+		count := 0
+		for _, section := range sections {
+			count += len(section.Items)
+		}
+
+		var buf bytes.Buffer
+		view.NewRenderer(&buf, itemstore).PrintMarkdown(func() []view.Section { return sections }, exportDetails)
+		if exportFile == "" {
+			os.Stdout.Write(buf.Bytes())
+			return
+		}
+		if err := ioutil.WriteFile(exportFile, buf.Bytes(), 0644); err != nil {
+			view.Failure(`:-O`, "Could not write "+exportFile+" because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		view.Success(`:-)`, "Exported "+strconv.Itoa(count)+" items to "+exportFile)
+		fmt.Println()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(&exportFormat, "format", "markdown", "format to export in; only markdown for now")
+	exportCmd.Flags().StringVarP(&exportFile, "file", "o", "", "write to a file instead of the terminal")
+	exportCmd.Flags().BoolVar(&exportDetails, "details", false, "add each item's number, state and dates")
+}
@@ -23,28 +23,43 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/kalexmills/collabbook-go/view"
 	"github.com/spf13/cobra"
 )
 
 // findCmd represents the find command
 var findCmd = &cobra.Command{
-	Use:     "find",
+	Use:     "find query",
 	Aliases: []string{"f"},
 	Short:   "Search for items",
 	DisableFlagsInUseLine: true,
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Long: `
+Lists the items matching a query, grouped by board. Matching items in the
+archive are listed last. The simplest queries are words, and match items
+whose descriptions contain every one of them, ignoring case.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+Examples:
+
+   cb find milk
+   cb find buy milk --output ndjson
+   cb find '"flaky test"' is:open 'created:>2026-09-01'
+   cb find 'milk or eggs'
+   cb find milk --group-by none --sort created
+`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("find called")
+		query := itemQuery(args)
+		newRenderer().PrintSections(groupedSections(matching(query, itemstore.Items), view.GroupByBoard, view.ById))
+		if textOutput() {
+			fmt.Println()
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(findCmd)
+	addListingFlags(findCmd)
+	findCmd.Long += queryHelp
 
 	// Here you will define your flags and configuration settings.
 
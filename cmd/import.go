@@ -0,0 +1,312 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+)
+
+var importFormat string
+var importDryRun bool
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a Markdown checklist",
+	DisableFlagsInUseLine: true,
+	Long: `
+Creates items from the lists in a Markdown file, or from standard input when
+the file is -. Entries like - [ ] become tasks, and - [x] tasks already done;
+other bullets and numbered entries become notes. Bold entries are starred,
+and tasks struck through with ~~ are cancelled. Entries of nested lists are
+imported alongside their parents, with a warning for each. Code blocks,
+whether fenced or indented, and thematic breaks such as * * * are skipped.
+
+Items are put on a board named after the heading above them: headings which
+are already board names, such as #sprint-12 or My board, are used as they
+are, and others are turned into one, so that "Release plan" becomes
+#release-plan. Items above the first heading go on the default board.
+
+Files written by 'cb export' can be imported again, keeping the states given
+by --details.
+
+With --dry-run, the items are listed as they would be created, but the book
+is left unchanged.
+
+Examples:
+
+   cb import notes.md
+   cb import --format markdown --dry-run notes.md
+   cb export #sprint-12 --details | cb import -
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if importFormat != "markdown" {
+			view.Failure(`:-\`, fmt.Sprintf("unknown import format %q; expected markdown", importFormat))
+			fmt.Println()
+			os.Exit(1)
+		}
+		var text []byte
+		var err error
+		if args[0] == "-" {
+			text, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			text, err = ioutil.ReadFile(args[0])
+		}
+		if err != nil {
+			view.Failure(`:-(`, "Could not read "+args[0]+" because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		entries, warnings := parseMarkdownList(text)
+		if len(entries) == 0 {
+			view.Failure(`:-\`, "No tasks or notes found in "+args[0])
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		// A dry run imports into a copy of the book, which is thrown away.
+		store := itemstore
+		if importDryRun {
+			store = scratchCopy(itemstore)
+		}
+		created := make(map[uint64]bool, len(entries))
+		for _, entry := range entries {
+			it, err := importEntry(store, entry)
+			if err != nil {
+				view.Failure(`:-O`, "Could not import "+strconv.Quote(entry.desc)+" because:\n\t"+err.Error())
+				fmt.Println()
+				os.Exit(1)
+			}
+			created[it.Id] = true
+		}
+		rendererFor(store).PrintSections(view.GroupedSections(store, func() []*data.Item {
+			var items []*data.Item
+			for _, it := range store.Items() {
+				if created[it.Id] {
+					items = append(items, it)
+				}
+			}
+			return items
+		}, itemGrouping(view.GroupByBoard), itemOrder(view.ById)))
+
+		if !textOutput() {
+			return
+		}
+		count := strconv.Itoa(len(entries))
+		if importDryRun {
+			view.Success(`:-|`, "Would import "+count+" items; run again without --dry-run to import them")
+		} else {
+			view.Success(`:-)`, "Imported "+count+" items from "+args[0])
+		}
+		for _, warning := range warnings {
+			view.Detail(warning)
+		}
+		fmt.Println()
+	},
+}
+
+// markdownEntry is an entry of a Markdown list, and the board it is to be imported onto.
+type markdownEntry struct {
+	board string
+	task  bool
+	state data.State
+	star  bool
+	desc  string
+}
+
+var (
+	markdownHeading = regexp.MustCompile(`^ {0,3}#{1,6}\s+(.*?)(?:\s+#+)?\s*$`)
+	markdownFence   = regexp.MustCompile("^ {0,3}(```|~~~)")
+	markdownEntryRe = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(?:\[([ xX])\](?:\s+|$))?(.*)$`)
+	// markdownBreak matches a thematic break, such as --- or * * *, which would otherwise look like an entry.
+	markdownBreak = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	// markdownDetails matches the details written by cb export --details.
+	markdownDetails = regexp.MustCompile(`\s+_\(id \d+((?:, [^,()]+)*)\)_$`)
+	// markdownEscaped matches a backslash escaping ASCII punctuation, as CommonMark allows.
+	markdownEscaped = regexp.MustCompile("\\\\([!-/:-@[-`{-~])")
+)
+
+// parseMarkdownList returns the entries of every list in a Markdown document, skipping code blocks, thematic breaks and
+// entries without text. Nested lists are flattened, with a warning for each nested entry, since items cannot contain
+// other items.
+func parseMarkdownList(text []byte) ([]markdownEntry, []string) {
+	var result []markdownEntry
+	var warnings []string
+	board := data.DefaultBoard
+	fenced := false
+	listIndent := -1 // indentation of the entries of the list being read, or -1 outside lists
+	s := bufio.NewScanner(bytes.NewReader(text))
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if markdownFence.MatchString(line) {
+			fenced = !fenced
+			listIndent = -1
+			continue
+		}
+		if fenced || strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := indentWidth(line)
+		if listIndent < 0 && indent >= 4 {
+			// Indented code, which only lists may contain.
+			continue
+		}
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			board = boardOfHeading(m[1])
+			listIndent = -1
+			continue
+		}
+		if markdownBreak.MatchString(line) {
+			listIndent = -1
+			continue
+		}
+		m := markdownEntryRe.FindStringSubmatch(line)
+		if m == nil {
+			if indent <= listIndent {
+				listIndent = -1
+			}
+			continue
+		}
+
+		entry := markdownEntry{board: board, task: m[1] != "", desc: strings.TrimSpace(m[2])}
+		if m[1] == "x" || m[1] == "X" {
+			entry.state = data.Done
+		}
+		if d := markdownDetails.FindStringSubmatch(entry.desc); d != nil {
+			entry.desc = strings.TrimSuffix(entry.desc, d[0])
+			for _, detail := range strings.Split(d[1], ", ") {
+				if state, err := data.ParseState(detail); err == nil && entry.task {
+					entry.state = state
+				}
+			}
+		}
+		if entry.task && strings.HasPrefix(entry.desc, "~~") && strings.HasSuffix(entry.desc, "~~") && len(entry.desc) > 4 {
+			entry.desc = entry.desc[2 : len(entry.desc)-2]
+			entry.state = data.Cancelled
+		}
+		if strings.HasPrefix(entry.desc, "**") && strings.HasSuffix(entry.desc, "**") && len(entry.desc) > 4 {
+			entry.desc = entry.desc[2 : len(entry.desc)-2]
+			entry.star = true
+		}
+		entry.desc = markdownUnescape(entry.desc)
+		if entry.desc == "" {
+			continue
+		}
+
+		if listIndent < 0 {
+			listIndent = indent
+		} else if indent > listIndent {
+			warnings = append(warnings, fmt.Sprintf("line %d: nested entry %q imported onto %s alongside the entry above it",
+				n, entry.desc, board))
+		}
+		result = append(result, entry)
+	}
+	return result, warnings
+}
+
+// indentWidth returns the number of columns text is indented by, with tabs stopping every four columns.
+func indentWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// markdownUnescape removes the backslashes escaping punctuation in Markdown text, such as those written by cb export.
+func markdownUnescape(text string) string {
+	return markdownEscaped.ReplaceAllString(text, "$1")
+}
+
+// boardOfHeading returns the board named by a heading, once unescaped: the heading itself when it is a board name, or
+// else the heading in lowercase with dashes for spaces, after a #.
+func boardOfHeading(heading string) string {
+	heading = markdownUnescape(heading)
+	if isBoardArg(heading) || heading == data.DefaultBoard || heading == data.ArchiveBoard {
+		return heading
+	}
+	return "#" + strings.Join(strings.Fields(strings.ToLower(heading)), "-")
+}
+
+// importEntry creates the item for a Markdown entry in store, returning a snapshot of it.
+func importEntry(store *data.Repo, entry markdownEntry) (*data.Item, error) {
+	var it *data.Item
+	if entry.task {
+		it = store.MakeTask(entry.desc, entry.board)
+	} else {
+		it = store.MakeNote(entry.desc, entry.board)
+	}
+	if entry.star {
+		it = store.ToggleItemIsStarred(it.Id)
+	}
+	if entry.task && entry.state != data.Todo {
+		updated, err := store.SetTaskState(it.Id, entry.state)
+		if err != nil {
+			return nil, err
+		}
+		it = updated
+	}
+	return it, nil
+}
+
+// scratchCopy returns a copy of store which can be changed without affecting it.
+func scratchCopy(store *data.Repo) *data.Repo {
+	result := data.NewRepo()
+	text, err := store.MarshalText()
+	if err == nil {
+		err = result.UnmarshalText(text)
+	}
+	if err != nil {
+		view.Failure(`:-O`, "Could not copy the book because:\n\t"+err.Error())
+		fmt.Println()
+		os.Exit(1)
+	}
+	return result
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importFormat, "format", "markdown", "format to import from; only markdown for now")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "list the items which would be created, but change nothing")
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+)
+
+func TestParseMarkdownList(t *testing.T) {
+	text := strings.Join([]string{
+		"- loose note",
+		"# Release plan",
+		"- [ ] open task",
+		"- [x] done task",
+		"* [X] also done",
+		"1. numbered note",
+		"2) another",
+		"- **starred**",
+		"- [ ] ~~cancelled~~",
+		"- [ ] begun _(id 3, in-progress, created 2026-10-01)_",
+		"-",
+		"- [ ]",
+		"",
+		"* * *",
+		"---",
+		"___",
+		" - - -",
+		"",
+		"Some text.",
+		"",
+		"    - indented code",
+		"\t* tabbed code",
+		"",
+		"```",
+		"- fenced",
+		"```",
+		"## #sprint-12",
+		"- \\[ \\] escaped \\*not bold\\* 1\\. R\\&D",
+		"- \\---",
+	}, "\n")
+
+	want := []markdownEntry{
+		{board: data.DefaultBoard, desc: "loose note"},
+		{board: "#release-plan", task: true, desc: "open task"},
+		{board: "#release-plan", task: true, state: data.Done, desc: "done task"},
+		{board: "#release-plan", task: true, state: data.Done, desc: "also done"},
+		{board: "#release-plan", desc: "numbered note"},
+		{board: "#release-plan", desc: "another"},
+		{board: "#release-plan", star: true, desc: "starred"},
+		{board: "#release-plan", task: true, state: data.Cancelled, desc: "cancelled"},
+		{board: "#release-plan", task: true, state: data.InProgress, desc: "begun"},
+		{board: "#sprint-12", desc: "[ ] escaped *not bold* 1. R&D"},
+		{board: "#sprint-12", desc: "---"},
+	}
+	got, warnings := parseMarkdownList([]byte(text))
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d:\n%+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d is %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if len(warnings) != 0 {
+		t.Errorf("got warnings %v, want none", warnings)
+	}
+}
+
+func TestParseMarkdownListNested(t *testing.T) {
+	text := "- parent\n  - child\n    - grandchild\n\n  more about the parent\n- sibling\n"
+	got, warnings := parseMarkdownList([]byte(text))
+	var descs []string
+	for _, entry := range got {
+		descs = append(descs, entry.desc)
+	}
+	if strings.Join(descs, ",") != "parent,child,grandchild,sibling" {
+		t.Errorf("got entries %v, want every entry flattened", descs)
+	}
+	if len(warnings) != 2 || !strings.HasPrefix(warnings[0], "line 2:") || !strings.HasPrefix(warnings[1], "line 3:") {
+		t.Errorf("got warnings %v, want one for each nested entry", warnings)
+	}
+}
+
+// TestMarkdownRoundTrip checks that items exported as Markdown import with the same descriptions and states.
+func TestMarkdownRoundTrip(t *testing.T) {
+	store := data.NewRepo()
+	descs := []string{
+		"[ ] looks like a task",
+		"*stars* and _underscores_ and ~~strikes~~",
+		"# not a heading",
+		"1. not a number",
+		"- not a nested list",
+		"---",
+		"<b>html</b> | pipes & ampersands",
+		`back\slash and \*escaped\*`,
+		"`code`",
+	}
+	for _, desc := range descs {
+		store.MakeNote(desc, "#notes")
+	}
+	task := store.MakeTask("blocked [x] task", "#notes")
+	store.SetTaskState(task.Id, data.Blocked)
+	store.ToggleItemIsStarred(task.Id)
+
+	store.MakeNote("on a board with underscores", "#release_notes")
+
+	var out bytes.Buffer
+	r := view.NewRenderer(&out, store)
+	r.PrintMarkdown(func() []view.Section {
+		var sections []view.Section
+		for _, board := range []string{"#notes", "#release_notes"} {
+			heading := board
+			sections = append(sections, view.Section{Heading: &heading, Items: store.IdsInBoard(board)})
+		}
+		return sections
+	}, true)
+
+	entries, _ := parseMarkdownList(out.Bytes())
+	if len(entries) != len(descs)+2 {
+		t.Fatalf("got %d entries from\n%s", len(entries), out.String())
+	}
+	for i, entry := range entries {
+		it := store.Item(uint64(i))
+		if entry.desc != it.Desc || entry.task != it.IsTask() || entry.star != it.IsStarred() ||
+			entry.board != store.BoardsOf(it.Id)[0] || (it.IsTask() && entry.state != it.State()) {
+			t.Errorf("%q came back as %+v from\n%s", it.Desc, entry, out.String())
+		}
+	}
+}
+
+func TestImportEntry(t *testing.T) {
+	store := data.NewRepo()
+	it, err := importEntry(store, markdownEntry{board: "#b", task: true, state: data.Blocked, star: true, desc: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.State() != data.Blocked || !it.IsStarred() {
+		t.Errorf("imported %+v", it)
+	}
+	if boards := store.BoardsOf(it.Id); len(boards) != 1 || boards[0] != "#b" {
+		t.Errorf("imported onto %v, want #b", boards)
+	}
+}
@@ -43,6 +43,7 @@ to quickly create a Cobra application.`,
 	// PersistentPreRun acts as a noop to override the default implementation
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		itemstore = data.NewRepo()
+		itemstore.RecordChanges()
 	},
 	// Run creates a new collabbook file in the present directory.
 	Run: func(cmd *cobra.Command, args []string) {
@@ -53,6 +54,7 @@ to quickly create a Cobra application.`,
 		}
 
 		path := filepath.Join(wd, ".collabbook")
+		cbPath = path
 
 		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
 		if err != nil {
@@ -15,8 +15,8 @@ func ItemCreate(name string, factory func(desc string, boards ...string) (*data.
 		boards := make([]string, 0)
 		// Extract board names from arguments
 		for _, arg := range args {
-			if arg[0] == '#' || arg[0] == '@' {
-				boards = append(boards[1:], arg)
+			if isBoardArg(arg) {
+				boards = append(boards, arg)
 			} else {
 				b.WriteString(arg)
 				b.WriteRune(' ')
@@ -31,4 +31,9 @@ func ItemCreate(name string, factory func(desc string, boards ...string) (*data.
 		item := factory(desc,boards...)
 		view.Success(`:-)`, "Created " + name + ": " + strconv.FormatUint(item.Id, 10))
 	}
-}
\ No newline at end of file
+}
+
+// isBoardArg reports whether a command-line argument names a board.
+func isBoardArg(arg string) bool {
+	return len(arg) > 0 && (arg[0] == '#' || arg[0] == '@')
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+)
+
+// lookupItems resolves each argument to an item, accepting either display numbers or Uids. If any argument fails to
+// resolve, the failures are reported and the process exits before anything is changed.
+func lookupItems(args []string) []*data.Item {
+	items := make([]*data.Item, 0, len(args))
+	failed := false
+	for _, arg := range args {
+		item, err := itemstore.Lookup(arg)
+		if err != nil {
+			view.Failure(`:-\`, err.Error())
+			failed = true
+			continue
+		}
+		items = append(items, item)
+	}
+	if failed {
+		os.Exit(1)
+	}
+	return items
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/spf13/viper"
+)
+
+// journal records the changes made by each mutating command run against a book, so that they can be undone and
+// redone later. Entries before Cursor have been applied; entries from Cursor onwards have been undone.
+type journal struct {
+	Cursor  int
+	Entries []journalEntry
+}
+
+type journalEntry struct {
+	Command string
+	Time    time.Time
+	Changes []data.Change
+}
+
+// steppedJournal is the journal as left by undo or redo, which persistChanges saves only once the book they changed
+// has been saved, so that the two never disagree about which changes have been undone.
+var steppedJournal *journal
+
+// loadJournal reads the journal of the book at path. Books without a journal have an empty one.
+func loadJournal(path string) (*journal, error) {
+	text, err := ioutil.ReadFile(bookDir(path, "journal"))
+	if os.IsNotExist(err) {
+		return &journal{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &journal{}
+	if err := json.Unmarshal(text, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// save writes the journal of the book at path, replacing the previous journal in one step.
+func (j *journal) save(path string) error {
+	text, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(bookDir(path), 0755); err != nil {
+		return err
+	}
+	tmp := bookDir(path, "journal.tmp")
+	if err := ioutil.WriteFile(tmp, text, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, bookDir(path, "journal"))
+}
+
+// recordJournal adds the changes made by a command to the journal of the book at path. Anything which had been undone
+// can no longer be redone afterwards, and the oldest entries beyond undo.limit are forgotten.
+func recordJournal(path string, command string, changes []data.Change) error {
+	j, err := loadJournal(path)
+	if err != nil {
+		return err
+	}
+	j.Entries = append(j.Entries[:j.Cursor], journalEntry{command, time.Now().UTC(), changes})
+	if limit := viper.GetInt("undo.limit"); limit > 0 && len(j.Entries) > limit {
+		j.Entries = j.Entries[len(j.Entries)-limit:]
+	}
+	j.Cursor = len(j.Entries)
+	return j.save(path)
+}
@@ -0,0 +1,123 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+)
+
+var kanbanAllBoards bool
+
+// kanbanCmd represents the kanban command
+var kanbanCmd = &cobra.Command{
+	Use:     "kanban",
+	Aliases: []string{"k"},
+	Short:   "Display boards side by side",
+	DisableFlagsInUseLine: true,
+	Long: `
+Lays out tasks side by side in columns fitted to the width of the terminal.
+Without arguments, there is a column for each state a task can be in, todo,
+in-progress, blocked, done and, when there are any, cancelled, holding the
+tasks from every board except the archive. Given boards, or --boards for
+all of them, there is a column for each board instead. Columns which do not
+fit beside each other continue below.
+
+Examples:
+
+   cb kanban
+   cb kanban #sprint-12 #release
+   cb kanban "My board" archive
+   cb kanban --boards --truncate
+   cb kanban --sort priority
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, arg := range args {
+			if !isBoardArg(arg) && arg != data.DefaultBoard && arg != data.ArchiveBoard {
+				view.Failure(`:-\`, "Board names start with # or @, or are \""+data.DefaultBoard+"\" or "+
+					data.ArchiveBoard+", not "+arg)
+				fmt.Println()
+				os.Exit(1)
+			}
+		}
+
+		newRenderer().PrintColumns(func() []view.Section {
+			switch {
+			case len(args) > 0:
+				sections := make([]view.Section, len(args))
+				for i, board := range args {
+					sections[i] = boardSection(board, nil)
+				}
+				return sections
+			case kanbanAllBoards:
+				return boardSections(nil)
+			}
+			return statusSections(itemstore)
+		})
+		if textOutput() {
+			fmt.Println()
+		}
+	},
+}
+
+// statusSections returns a section for each state a task may be in, listing the tasks outside the archive. Cancelled
+// tasks only get a column when there are some.
+func statusSections(store *data.Repo) []view.Section {
+	sections := make([]view.Section, len(data.States))
+	for i, state := range data.States {
+		heading := state.String()
+		sections[i].Heading = &heading
+	}
+	for _, it := range store.ActiveItems() {
+		if it.IsTask() {
+			sections[it.State()].Items = append(sections[it.State()].Items, it.Id)
+		}
+	}
+	order := itemOrder(view.ById)
+	for i, section := range sections {
+		items := make([]*data.Item, len(section.Items))
+		for j, id := range section.Items {
+			items[j] = store.Item(id)
+		}
+		view.SortItems(items, order)
+		for j, it := range items {
+			sections[i].Items[j] = it.Id
+		}
+	}
+	if len(sections[data.Cancelled].Items) == 0 {
+		sections = sections[:data.Cancelled]
+	}
+	return sections
+}
+
+func init() {
+	rootCmd.AddCommand(kanbanCmd)
+
+	kanbanCmd.Flags().BoolVar(&kanbanAllBoards, "boards", false, "show a column for every board")
+	kanbanCmd.Flags().BoolVar(&truncateItems, "truncate", false,
+		"cut long descriptions short to fit their column instead of wrapping them")
+	addSortFlag(kanbanCmd)
+}
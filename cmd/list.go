@@ -21,48 +21,49 @@
 package cmd
 
 import (
-	"github.com/kalexmills/collabbook-go/data"
+	"fmt"
+
 	"github.com/kalexmills/collabbook-go/view"
 	"github.com/spf13/cobra"
-	"fmt"
 )
 
 // listCmd represents the list command
 var listCmd = &cobra.Command{
-	Use:     "list",
+	Use:     "list [query]",
 	Aliases: []string{"l"},
 	Short:   "List items by attributes",
 	DisableFlagsInUseLine: true,
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Long: `
+Lists the items on every board except the archive, starting with the default
+board and followed by the others in alphabetical order. Items are listed in
+order of their numbers within each board. Given a query, only the items
+matching it are listed.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		view.PrintSections(itemstore, func() []view.Section {
-			boards := itemstore.Boards()
-
-			sections := make([]view.Section, len(boards)-1)
+With --sort, items are ordered instead by when they were created (created),
+starred items first and then by status (priority), work in progress first
+and finished work last (status), or alphabetically by description (desc).
+With --group-by, items are split by their status or the day they were
+created instead of by board, or not split at all (none).
 
-			i := 1
-			for j := 0; j < len(boards); j += 1 {
-				if boards[j] == data.ArchiveBoard {
-					continue
-				}
-				if boards[j] == data.DefaultBoard {
-					sections[0] = view.Section{&boards[j], itemstore.IdsInBoard(boards[j])}
-					continue
-				}
-				sections[i] = view.Section{&boards[j], itemstore.IdsInBoard(boards[j])}
-			}
+Examples:
 
-			return sections
-		})
-		fmt.Println()
+   cb list
+   cb list --sort priority
+   cb list --group-by status --sort created
+   cb list is:open board:#release starred
+   cb list --output json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		query := itemQuery(args)
+		newRenderer().PrintSections(groupedSections(matching(query, itemstore.ActiveItems), view.GroupByBoard, view.ById))
+		if textOutput() {
+			fmt.Println()
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
+	addListingFlags(listCmd)
+	listCmd.Long += queryHelp
 }
@@ -0,0 +1,99 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+)
+
+// logCmd represents the log command
+var logCmd = &cobra.Command{
+	Use:   "log [id]",
+	Short: "Show the history of the book or an item",
+	DisableFlagsInUseLine: true,
+	Long: `
+Shows every change recorded in the event log, oldest first, with when it was
+made and by whom. Given an item, by display number or unique id, only the
+changes to that item are shown; deleted items can be found by unique id.
+
+The event log is kept only when eventlog.enabled is set in the config file.
+The name recorded for each change is taken from the author option, falling back
+to the current user. A snapshot of the book is taken every 100 events, or as
+set by eventlog.snapshot-every.
+
+Examples:
+
+   cb log
+   cb log 3
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !eventLogEnabled() {
+			view.Failure(`:-\`, "The event log is not enabled. Set eventlog.enabled in your config file to start one.")
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		uid := ""
+		if len(args) > 0 {
+			if item, err := itemstore.Lookup(args[0]); err == nil {
+				uid = item.Uid
+			} else if data.IsUid(args[0]) {
+				uid = strings.ToUpper(args[0])
+			} else {
+				view.Failure(`:-\`, err.Error())
+				fmt.Println()
+				os.Exit(1)
+			}
+		}
+
+		events, err := readEvents(cbPath)
+		if err != nil {
+			view.Failure(`:-O`, "Could not read the event log because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		fmt.Println()
+		for _, event := range events {
+			if uid != "" && !changeTouches(event.Change, uid) {
+				continue
+			}
+			fmt.Printf("  %s  %s  %s\n", event.Time.Local().Format("2006-01-02 15:04"), event.Author, event.Change)
+		}
+	},
+}
+
+// changeTouches reports whether change affected the item with the given uid.
+func changeTouches(change data.Change, uid string) bool {
+	return (change.Before != nil && change.Before.Uid == uid) || (change.After != nil && change.After.Uid == uid)
+}
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+}
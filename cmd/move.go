@@ -22,24 +22,53 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
+	"github.com/kalexmills/collabbook-go/view"
 	"github.com/spf13/cobra"
 )
 
 // moveCmd represents the move command
 var moveCmd = &cobra.Command{
-	Use:     "move",
+	Use:     "move <items...> <boards...>",
 	Aliases: []string{"m"},
 	Short:   "Move item between boards",
 	DisableFlagsInUseLine: true,
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Long: `
+Moves one or more items onto the given boards, taking them off every board they
+were on before. Any argument starting with either '#' or '@' is interpreted as
+the name of a board; the rest refer to items, either by display number or by
+unique id. Boards which do not already exist are created.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+Examples:
+
+   cb move 3 #done
+   cb move 3 4 #sprint-12 #release
+   cb move 01HB8ZK3M4Q2V6W8X9Y0Z1A2B3 #sprint-12
+`,
+	Args: cobra.MinimumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("move called")
+		var boards, refs []string
+		for _, arg := range args {
+			if isBoardArg(arg) {
+				boards = append(boards, arg)
+			} else {
+				refs = append(refs, arg)
+			}
+		}
+		if len(boards) == 0 {
+			view.Failure(`:-\`, "No board given to move to")
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		for _, item := range lookupItems(refs) {
+			itemstore.MoveItem(item.Id, boards...)
+			view.Success(`:-)`, "Moved item "+strconv.FormatUint(item.Id, 10)+" to "+strings.Join(boards, ", "))
+		}
+		fmt.Println()
 	},
 }
 
@@ -0,0 +1,407 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// outputFormat is the value of the global --output flag.
+var outputFormat string
+
+// colorMode and noColor are the values of the global --color and --no-color flags.
+var colorMode string
+var noColor bool
+
+// itemFormat and truncateItems are the values of the --format and --truncate flags of listing commands.
+var itemFormat string
+var truncateItems bool
+
+// sortOrder and groupBy are the values of the --sort and --group-by flags of listing commands. When empty, each
+// command uses its own default.
+var sortOrder string
+var groupBy string
+
+// newRenderer returns a renderer which writes to the terminal in the format chosen by --output.
+func newRenderer() *view.Renderer {
+	return rendererFor(itemstore)
+}
+
+// rendererFor is newRenderer for items held in store rather than the book the command opened.
+func rendererFor(store *data.Repo) *view.Renderer {
+	format, err := view.ParseFormat(outputFormat)
+	if err != nil {
+		view.Failure(`:-\`, err.Error())
+		fmt.Println()
+		os.Exit(1)
+	}
+	theme, err := configuredTheme()
+	if err != nil {
+		view.Failure(`:-\`, "Could not read the theme in the config file because:\n\t"+err.Error())
+		fmt.Println()
+		os.Exit(1)
+	}
+	result := view.NewRenderer(color.Output, store)
+	result.SetFormat(format)
+	result.SetTheme(theme)
+	result.SetWidth(terminalWidth(), truncateItems)
+	if itemFormat != "" {
+		tmpl, err := view.ParseTemplate(itemTemplate(itemFormat))
+		if err != nil {
+			view.Failure(`:-\`, "Could not read --format because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		result.SetTemplate(tmpl)
+	}
+	return result
+}
+
+// configuredTheme returns the theme chosen in the config file, either by name alone,
+//
+//	theme: mono
+//
+// or as one of the built-in themes with some of its colors and glyphs overridden,
+//
+//	theme:
+//	  name: default
+//	  colors:
+//	    heading: bold+hi-white
+//	  glyphs:
+//	    done: "[✔]"
+func configuredTheme() (view.Theme, error) {
+	name := viper.GetString("theme")
+	if name == "" {
+		name = viper.GetString("theme.name")
+	}
+	if name == "" {
+		name = "default"
+	}
+	theme, ok := view.Themes[name]
+	if !ok {
+		return theme, fmt.Errorf("unknown theme %q; expected one of %s", name, strings.Join(view.ThemeNames(), ", "))
+	}
+
+	for element, spec := range viper.GetStringMapString("theme.colors") {
+		style, err := view.ParseStyle(spec)
+		if err != nil {
+			return theme, err
+		}
+		if err := theme.SetStyle(element, style); err != nil {
+			return theme, err
+		}
+	}
+	for name, glyph := range viper.GetStringMapString("theme.glyphs") {
+		if err := theme.SetGlyph(name, glyph); err != nil {
+			return theme, err
+		}
+	}
+	return theme, nil
+}
+
+// terminalWidth returns the number of columns given by the COLUMNS environment variable, or else the width of the
+// terminal. Output which is not going to a terminal has no width, so that descriptions are never wrapped in files.
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		return width
+	}
+	return 0
+}
+
+// itemTemplate returns the text of the template named by format in the config file, or format itself when no
+// template has that name.
+func itemTemplate(format string) string {
+	if named := viper.GetString("templates." + format); named != "" {
+		return named
+	}
+	return format
+}
+
+// addListingFlags adds the --format and --truncate flags to a listing command.
+func addListingFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&itemFormat, "format", "",
+		"print each item using a Go template, or a template named in the config file")
+	cmd.Flags().BoolVar(&truncateItems, "truncate", false,
+		"cut long descriptions short to fit the terminal instead of wrapping them")
+	addSortFlag(cmd)
+	cmd.Flags().StringVar(&groupBy, "group-by", "",
+		"split the items into sections by board, status or day, or none for a single section")
+	cmd.Long += formatHelp
+}
+
+// itemQuery reads the query given as the arguments of a listing command, which matches every item when there are no
+// arguments.
+func itemQuery(args []string) data.Query {
+	return parseItemQuery(data.JoinQueryArgs(args))
+}
+
+// parseItemQuery reads the text of a query, such as one saved in a view.
+func parseItemQuery(text string) data.Query {
+	query, err := data.ParseQuery(text)
+	if err != nil {
+		view.Failure(`:-\`, "Could not read the query because:\n\t"+err.Error())
+		fmt.Println()
+		os.Exit(1)
+	}
+	return query
+}
+
+// matching returns a function listing the items returned by items which match query.
+func matching(query data.Query, items func() []*data.Item) func() []*data.Item {
+	return func() []*data.Item {
+		var result []*data.Item
+		for _, it := range items() {
+			if query.Match(it, itemstore.BoardsOf(it.Id)) {
+				result = append(result, it)
+			}
+		}
+		return result
+	}
+}
+
+// addSortFlag adds the --sort flag to a command which lists items.
+func addSortFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sortOrder, "sort", "",
+		"order the items in each section by id, created, priority, status or desc")
+}
+
+// itemOrder returns the ordering chosen by --sort, or fallback when it was not given.
+func itemOrder(fallback view.Ordering) view.Ordering {
+	if sortOrder == "" {
+		return fallback
+	}
+	order, err := view.ParseOrdering(sortOrder)
+	if err != nil {
+		view.Failure(`:-\`, err.Error())
+		fmt.Println()
+		os.Exit(1)
+	}
+	return order
+}
+
+// itemGrouping returns the grouping chosen by --group-by, or fallback when it was not given.
+func itemGrouping(fallback view.Grouping) view.Grouping {
+	if groupBy == "" {
+		return fallback
+	}
+	group, err := view.ParseGrouping(groupBy)
+	if err != nil {
+		view.Failure(`:-\`, err.Error())
+		fmt.Println()
+		os.Exit(1)
+	}
+	return group
+}
+
+// groupedSections returns a section factory listing the items returned by items, grouped and sorted as chosen by
+// --group-by and --sort, or else by group and order.
+func groupedSections(items func() []*data.Item, group view.Grouping, order view.Ordering) func() []view.Section {
+	return view.GroupedSections(itemstore, items, itemGrouping(group), itemOrder(order))
+}
+
+// applyColorMode turns colors on or off for every command according to --color and --no-color. In auto mode, colors
+// are only used when writing to a terminal, and never when the NO_COLOR environment variable is set.
+func applyColorMode() {
+	if noColor {
+		colorMode = "never"
+	}
+	switch colorMode {
+	case "always":
+		color.NoColor = false
+	case "never":
+		color.NoColor = true
+	case "auto":
+		color.NoColor = os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" ||
+			!(isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()))
+	default:
+		color.NoColor = true
+		view.Failure(`:-\`, fmt.Sprintf("unknown color mode %q; expected auto, always or never", colorMode))
+		fmt.Println()
+		os.Exit(1)
+	}
+}
+
+// textOutput reports whether output is meant for people rather than scripts. Blank lines used to space out text
+// are left out of machine-readable output.
+func textOutput() bool {
+	return outputFormat == string(view.Text)
+}
+
+// boardSections returns a section for each board, with DefaultBoard first and the rest in alphabetical order. The
+// archive is left out.
+func boardSections(keep func(*data.Item) bool) []view.Section {
+	boards := itemstore.Boards()
+	view.SortBoards(boards)
+
+	sections := make([]view.Section, 0, len(boards))
+	for i := range boards {
+		if boards[i] == data.ArchiveBoard {
+			continue
+		}
+		sections = append(sections, boardSection(boards[i], keep))
+	}
+	return sections
+}
+
+// boardSection returns a section listing the items on the named board, in the order chosen by --sort or else by
+// display number. When keep is not nil, only the items it returns true for are listed.
+func boardSection(name string, keep func(*data.Item) bool) view.Section {
+	var items []*data.Item
+	for _, it := range itemstore.ItemsInBoards(name) {
+		if keep == nil || keep(it) {
+			items = append(items, it)
+		}
+	}
+	view.SortItems(items, itemOrder(view.ById))
+
+	ids := make([]uint64, len(items))
+	for i, it := range items {
+		ids[i] = it.Id
+	}
+	return view.Section{Heading: &name, Items: ids}
+}
+
+const outputHelp = `
+Output formats:
+
+   Read commands (list, find, timeline, archive, show and stats) print colored
+   text by default. With --output json they print a single JSON document
+   instead, and with --output ndjson they print one JSON object per line.
+
+   Every item is written as:
+
+      {"id": 3, "uid": "01HB8ZK3M4Q2V6W8X9Y0Z1A2B3", "type": "task",
+       "complete": false, "state": "in-progress", "starred": true,
+       "description": "Buy milk",
+       "boards": ["My board"], "created": "2018-06-01T09:30:00Z",
+       "completed": "...", "reopened": "..."}
+
+   where type is "task" or "note", state is one of todo, in-progress,
+   blocked, done or cancelled and is left out for notes, times are RFC 3339,
+   and completed and reopened are left out when unknown.
+
+   list, find, timeline and archive print
+      {"sections": [{"heading": "My board", "items": [item, ...]}, ...],
+       "summary": {"done": 1, "todo": 2, "inProgress": 1, "blocked": 0,
+                   "pending": 3, "cancelled": 0, "notes": 0}}
+   or, as ndjson, one item per line with an added "section" field. pending
+   counts every open task, that is todo, inProgress and blocked together.
+
+   show prints {"items": [item, ...]}, or one item per line as ndjson.
+
+   stats prints {"perDay": [{"date", "completed"}], "perWeek": [{"from",
+   "created", "completed"}], "medianLeadTimeSeconds", "measured", "boards":
+   [{"name", "done", "open", "completed"}], "starred", "overdue", "oldest":
+   [item, ...]}.
+
+   Fields may be added to these objects in future, but are never renamed or
+   removed.
+`
+
+const formatHelp = `
+Item templates:
+
+   --format prints each item of text output using a Go text/template instead
+   of the usual row, for example
+
+      cb list --format '{{.Id}} {{.Desc}} {{join .Boards ","}}'
+
+   Templates may also be named in the config file and chosen by name:
+
+      templates:
+        compact: '{{.Id}}: {{truncate 40 .Desc}}'
+
+      cb list --format compact
+
+   Templates may use the fields .Id, .Uid, .Type ("task" or "note"), .Task,
+   .Complete, .State (todo, in-progress, blocked, done or cancelled), .Starred,
+   .Desc, .Boards, .Created, .Completed and .Reopened, and these functions:
+
+      join LIST SEP     joins a list, e.g. {{join .Boards ", "}}
+      color NAME TEXT   colors text white, red, yellow, green or blue
+      ago TIME          how long ago a time was, e.g. "2d 4h ago"
+      date TIME         a time like 2006-01-02 15:04
+      truncate N TEXT   text cut to at most N characters
+      checkbox .        the usual checkbox of the item
+      star .            the usual star of the item, when starred
+`
+
+const queryHelp = `
+Queries:
+
+   Items are chosen by a query made of terms separated by spaces, all of which
+   must match. Each argument is read as a term of its own, so quote a whole
+   query to use or, and, not or parentheses, and quote terms using < or >,
+   which the shell would otherwise read itself:
+
+      word or "a phrase"   the description contains the text, ignoring case
+      is:task, is:note     tasks or notes
+      is:open, is:closed   tasks still to be finished, or done or cancelled
+      is:todo, is:done...  tasks in a state: todo, in-progress, blocked, done
+                           or cancelled
+      starred, is:starred  starred items
+      board:#release       items on a board; the # or @ may be left out, and
+                           names with spaces quoted, as in board:"My board"
+      created:>2026-09-01  items created after a day; <, <=, >= and = compare
+                           too, and a day alone means that day
+      completed:<=DATE     tasks completed by a day
+
+   Put or between terms to match either of them, and not or - before a term
+   to match items it does not. Terms may be grouped in parentheses:
+
+      cb list 'is:open (board:#release or starred) not is:blocked'
+      cb find 'created:>2026-09-01'
+
+   As - also starts a flag, put -- before a query using it:
+
+      cb list -- is:open -is:blocked
+
+   Inside a quoted query, quote words which would otherwise be read as part of
+   it, such as "or" or "http://example.com":
+
+      cb find '"or" or "http://example.com"'
+`
+
+const themeHelp = `
+Themes:
+
+   The theme section of the config file chooses how listings are colored and
+   which glyphs mark items. It names one of the built-in themes, default, mono
+   or high-contrast, and may override the colors of the heading, id, done,
+   in-progress, blocked, pending, cancelled, note, starred and changed elements
+   and the pending, in-progress, blocked, done, cancelled, note and star glyphs:
+
+      theme:
+        name: mono
+        colors:
+          heading: bold+underline
+          done: hi-green
+        glyphs:
+          done: "[✔]"
+          star: "★ "
+
+   Colors are names like red or hi-red, optionally joined by "+" with bold,
+   faint, italic, underline or reverse, or none.
+`
+
+func init() {
+	cobra.OnInitialize(applyColorMode)
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto",
+		"when to color output: auto, always or never; auto colors terminals unless NO_COLOR is set")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "never color output, the same as --color=never")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", string(view.Text),
+		"output format of read commands: text, json or ndjson")
+	rootCmd.Long += outputHelp + themeHelp
+}
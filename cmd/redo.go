@@ -0,0 +1,81 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+)
+
+// redoCmd represents the redo command
+var redoCmd = &cobra.Command{
+	Use:   "redo [n]",
+	Short: "Redo undone changes",
+	DisableFlagsInUseLine: true,
+	Long: `
+Reapplies the changes made by the last n commands reverted by 'cb undo', one by
+default.
+
+Examples:
+
+   cb redo
+   cb redo 3
+`,
+	Args: cobra.MaximumNArgs(1),
+	// Annotations keep undo and redo out of the journal they are stepping through.
+	Annotations: map[string]string{"journal": "skip"},
+	Run: func(cmd *cobra.Command, args []string) {
+		n := journalSteps(args)
+		j, err := loadJournal(cbPath)
+		if err != nil {
+			view.Failure(`:-O`, "Could not read the journal because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		if j.Cursor == len(j.Entries) {
+			view.Failure(`:-\`, "Nothing to redo")
+			fmt.Println()
+			return
+		}
+
+		for i := 0; i < n && j.Cursor < len(j.Entries); i++ {
+			entry := j.Entries[j.Cursor]
+			j.Cursor += 1
+			for _, change := range entry.Changes {
+				itemstore.Apply(change)
+			}
+			view.Success(`:-)`, "Redid "+entry.Command)
+			for _, change := range entry.Changes {
+				view.Detail(change.String())
+			}
+		}
+		steppedJournal = j
+		fmt.Println()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(redoCmd)
+}
@@ -21,6 +21,7 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 
@@ -50,45 +51,129 @@ var rootCmd = &cobra.Command{
 `,
 	// PersistentPreRun crawls up the working directory, checking for a .collabbook file and loading it when it finds it.
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		wd, err := os.Getwd()
-
-		for !os.IsNotExist(err) && !os.IsPermission(err) {
-			cbPath = filepath.Join(wd, ".collabbook")
-			var info os.FileInfo
-			info, err = os.Lstat(cbPath)
-
-			if err == nil && info.Mode().IsRegular() {
-				var bytes []byte
-				bytes, err = ioutil.ReadFile(cbPath)
-
-				itemstore = data.NewRepo()
-				err = itemstore.UnmarshalText(bytes)
-				if err != nil {
-					fmt.Printf("Corrupted .collabbook file found at " + filepath.Join(wd, ".collabbook"))
-					os.Exit(1)
-				}
-				return
-			}
-
-			wd, _ = filepath.Split(wd)
-			wd = filepath.Clean(wd)
-			info, err = os.Lstat(wd)
+		cbPath = findCollabbook()
 
-			if wd == filepath.VolumeName(wd)+string(filepath.Separator) {
-				fmt.Printf("Could not find .collabbook file in any ancestor directory. Stopping at filesystem boundary.")
-				os.Exit(1)
-			}
+		var err error
+		itemstore, err = openCollabbook(cbPath)
+		if _, ok := err.(*eventLogError); ok {
+			fmt.Printf("Could not read the event log of %s:\n\t%s\n", cbPath, err)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Corrupted .collabbook file found at %s\nRun `cb doctor` for details.", cbPath)
+			os.Exit(1)
 		}
+		itemstore.RecordChanges()
 	},
+	// PersistentPostRun saves the book.
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
-		if bytes, err := itemstore.MarshalText(); err == nil {
-			ioutil.WriteFile(cbPath, bytes, 0644)
-		} else {
-			view.Failure(":-O", "Could not write file because:\n\t" + err.Error())
+		if err := persistChanges(cmd); err != nil {
+			view.Failure(":-O", err.Error())
 		}
 	},
 }
 
+// persistChanges saves the book, recording any changes made to it by cmd in the event log, when enabled, and in the
+// journal used by undo and redo. Commands annotated with journal=skip are left out of the journal, and the journal as
+// left by undo or redo is saved only after the book. Long-running commands may call persistChanges whenever they like
+// to save their work so far.
+func persistChanges(cmd *cobra.Command) error {
+	changes := itemstore.TakeChanges()
+	if eventLogEnabled() && len(changes) > 0 {
+		if err := recordEvents(cbPath, itemstore, changes); err != nil {
+			return fmt.Errorf("Could not record changes in the event log because:\n\t%v", err)
+		}
+	}
+	if err := saveCollabbook(cbPath, itemstore); err != nil {
+		return fmt.Errorf("Could not write file because:\n\t%v", err)
+	}
+	if steppedJournal != nil {
+		if err := steppedJournal.save(cbPath); err != nil {
+			return fmt.Errorf("Could not write the journal because:\n\t%v", err)
+		}
+		steppedJournal = nil
+	}
+	if len(changes) > 0 && cmd.Annotations["journal"] != "skip" {
+		if err := recordJournal(cbPath, cmd.Name(), changes); err != nil {
+			return fmt.Errorf("Could not record changes for undo because:\n\t%v", err)
+		}
+	}
+	return nil
+}
+
+// findCollabbook crawls up from the working directory, returning the path of the first .collabbook file it finds.
+func findCollabbook() string {
+	wd, err := os.Getwd()
+
+	for !os.IsNotExist(err) && !os.IsPermission(err) {
+		path := filepath.Join(wd, ".collabbook")
+		var info os.FileInfo
+		info, err = os.Lstat(path)
+
+		if err == nil && info.Mode().IsRegular() {
+			return path
+		}
+
+		wd, _ = filepath.Split(wd)
+		wd = filepath.Clean(wd)
+		info, err = os.Lstat(wd)
+
+		if wd == filepath.VolumeName(wd)+string(filepath.Separator) {
+			fmt.Printf("Could not find .collabbook file in any ancestor directory. Stopping at filesystem boundary.")
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("Could not find .collabbook file:\n\t%s", err)
+	os.Exit(1)
+	return ""
+}
+
+// loadCollabbook reads the book stored at path. Any data.ParseErrors are returned alongside the items which could be
+// read.
+func loadCollabbook(path string) (*data.Repo, error) {
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result := data.NewRepo()
+	return result, result.UnmarshalText(text)
+}
+
+// saveCollabbook writes store to the book at path, backing up the previous version first. Books which have not
+// changed are left alone. The new version is written to a temporary file and renamed into place, so that a failed
+// write never leaves a partial book behind.
+func saveCollabbook(path string, store *data.Repo) error {
+	return saveCollabbookKeeping(path, store, viper.GetInt("backups.keep"))
+}
+
+// saveCollabbookKeeping is saveCollabbook keeping at most keep backups, or every backup when keep is keepAllBackups,
+// and taking none when keep is otherwise not positive.
+func saveCollabbookKeeping(path string, store *data.Repo, keep int) error {
+	text, err := store.MarshalText()
+	if err != nil {
+		return err
+	}
+
+	previous, err := ioutil.ReadFile(path)
+	if err == nil {
+		if bytes.Equal(previous, text) {
+			return nil
+		}
+		if keep > 0 || keep == keepAllBackups {
+			if err := writeBackup(path, previous, keep); err != nil {
+				return err
+			}
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, text, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -123,6 +208,35 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		// Written to stderr so that it never ends up in JSON output or exported books.
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	}
+}
+
+// updateConfig rewrites the config file with change made to the settings written in it, creating the file in the home
+// directory if there is none. Unlike viper.WriteConfig, defaults which were never written down are left out.
+func updateConfig(change func(settings map[string]interface{})) error {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(home, ".collabbook.conf.yaml")
+	}
+
+	current := viper.New()
+	current.SetConfigFile(path)
+	if err := current.ReadInConfig(); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	settings := current.AllSettings()
+	change(settings)
+
+	updated := viper.New()
+	updated.SetConfigFile(path)
+	for key, value := range settings {
+		updated.Set(key, value)
 	}
+	return updated.WriteConfig()
 }
@@ -22,7 +22,9 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
 
+	"github.com/kalexmills/collabbook-go/view"
 	"github.com/spf13/cobra"
 )
 
@@ -32,14 +34,21 @@ var starCmd = &cobra.Command{
 	Aliases: []string{"s"},
 	Short:   "Star/unstar item",
 	DisableFlagsInUseLine: true,
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Long: `
+Toggles the star on one or more items. Items may be referred to either by their
+display number or by their unique id.
+`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("star called")
+		for _, item := range lookupItems(args) {
+			item = itemstore.ToggleItemIsStarred(item.Id)
+			if item.IsStarred() {
+				view.Success(`:-)`, "Starred item: "+strconv.FormatUint(item.Id, 10))
+			} else {
+				view.Success(`:-)`, "Unstarred item: "+strconv.FormatUint(item.Id, 10))
+			}
+		}
+		fmt.Println()
 	},
 }
 
@@ -0,0 +1,84 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+)
+
+var statsOpts data.StatsOptions
+var statsOverdueDays int
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show task completion statistics",
+	DisableFlagsInUseLine: true,
+	Long: `
+Shows a dashboard of the collabbook: how far along each board is, how many
+tasks were created and completed in each recent week, how many were completed
+on each recent day, the median time taken to complete a task after creating
+it, how many items are starred or overdue, and the tasks which have been open
+longest.
+
+Tasks carry no due dates, so any task left open for longer than --overdue-days
+counts as overdue.
+
+Examples:
+
+   cb stats
+   cb stats --days 14 --weeks 8
+   cb stats --no-color > stats.txt
+   cb stats --output json
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, flag := range []string{"days", "weeks", "oldest", "overdue-days"} {
+			if value, _ := cmd.Flags().GetInt(flag); value < 0 {
+				view.Failure(`:-\`, "--"+flag+" must not be negative, not "+strconv.Itoa(value))
+				fmt.Println()
+				os.Exit(1)
+			}
+		}
+		statsOpts.OverdueAfter = time.Duration(statsOverdueDays) * 24 * time.Hour
+		newRenderer().PrintStats(itemstore.Stats(time.Now(), statsOpts))
+		if textOutput() {
+			fmt.Println()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().IntVar(&statsOpts.Days, "days", 7, "number of days to show completions for")
+	statsCmd.Flags().IntVar(&statsOpts.Weeks, "weeks", 4, "number of weeks to show creations, completions and throughput for")
+	statsCmd.Flags().IntVar(&statsOpts.Oldest, "oldest", 5, "number of the oldest open tasks to list")
+	statsCmd.Flags().IntVar(&statsOverdueDays, "overdue-days", 14, "number of days a task may stay open before it is overdue")
+}
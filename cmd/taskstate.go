@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+)
+
+// setTaskStates returns a command which moves the tasks named by its arguments to state, reporting each one with
+// verb, e.g. "Began".
+func setTaskStates(state data.State, verb string) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		for _, item := range lookupItems(args) {
+			item, err := itemstore.SetTaskState(item.Id, state)
+			if err != nil {
+				view.Failure(`:-\`, err.Error())
+				continue
+			}
+			view.Success(`:-)`, verb+" task: "+strconv.FormatUint(item.Id, 10))
+		}
+		fmt.Println()
+	}
+}
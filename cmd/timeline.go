@@ -23,6 +23,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/kalexmills/collabbook-go/view"
 	"github.com/spf13/cobra"
 )
 
@@ -32,19 +33,28 @@ var timelineCmd = &cobra.Command{
 	Aliases: []string{"i"},
 	Short:   "Display timeline view",
 	DisableFlagsInUseLine: true,
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Long: `
+Lists every item outside the archive under the day on which it was created,
+oldest day first.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+Examples:
+
+   cb timeline
+   cb timeline --sort status
+   cb timeline --output ndjson
+`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("timeline called")
+		newRenderer().PrintSections(groupedSections(itemstore.ActiveItems, view.GroupByDay, view.ByCreated))
+		if textOutput() {
+			fmt.Println()
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(timelineCmd)
+	addListingFlags(timelineCmd)
 
 	// Here you will define your flags and configuration settings.
 
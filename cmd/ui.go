@@ -0,0 +1,85 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/kalexmills/collabbook-go/ui"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+)
+
+// uiCmd represents the ui command
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Open a full-screen interface",
+	DisableFlagsInUseLine: true,
+	Long: `
+Opens a full-screen interface showing each board as a pane of items. Changes
+are saved as soon as they are made, and each one can be undone separately
+with cb undo.
+
+Keys:
+
+   left, right, h, l, tab    move between boards
+   up, down, j, k            move between items
+   space                     check or uncheck the selected task
+   s                         star or unstar the selected item
+   e                         edit the description of the selected item
+   m                         move the selected item to other boards
+   /                         show only items matching a search
+   esc                       clear the search, or quit
+   q, ctrl-c                 quit
+
+Examples:
+
+   cb ui
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		theme, err := configuredTheme()
+		if err != nil {
+			view.Failure(`:-\`, "Could not read the theme in the config file because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		if color.NoColor {
+			theme = view.Theme{Glyphs: theme.Glyphs}
+		}
+
+		err = ui.Run(itemstore, theme, func() error {
+			return persistChanges(cmd)
+		})
+		if err != nil {
+			view.Failure(`:-O`, "Could not open the full-screen interface because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+}
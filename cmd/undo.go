@@ -0,0 +1,103 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Undo the last changes",
+	DisableFlagsInUseLine: true,
+	Long: `
+Reverts the changes made by the last n commands which changed the collabbook,
+one by default. Undone commands can be reapplied with 'cb redo', until another
+command changes the book. Only changes to items are undone; saving or deleting
+a view is not.
+
+The number of commands remembered is set by the undo.limit option in the
+config file, and defaults to 100.
+
+Examples:
+
+   cb undo
+   cb undo 3
+`,
+	Args: cobra.MaximumNArgs(1),
+	// Annotations keep undo and redo out of the journal they are stepping through.
+	Annotations: map[string]string{"journal": "skip"},
+	Run: func(cmd *cobra.Command, args []string) {
+		n := journalSteps(args)
+		j, err := loadJournal(cbPath)
+		if err != nil {
+			view.Failure(`:-O`, "Could not read the journal because:\n\t"+err.Error())
+			fmt.Println()
+			os.Exit(1)
+		}
+		if j.Cursor == 0 {
+			view.Failure(`:-\`, "Nothing to undo")
+			fmt.Println()
+			return
+		}
+
+		for i := 0; i < n && j.Cursor > 0; i++ {
+			j.Cursor -= 1
+			entry := j.Entries[j.Cursor]
+			view.Success(`:-)`, "Undid "+entry.Command)
+			for k := len(entry.Changes) - 1; k >= 0; k-- {
+				inverse := entry.Changes[k].Invert()
+				itemstore.Apply(inverse)
+				view.Detail(inverse.String())
+			}
+		}
+		steppedJournal = j
+		fmt.Println()
+	},
+}
+
+// journalSteps parses the optional step count given to undo and redo.
+func journalSteps(args []string) int {
+	if len(args) == 0 {
+		return 1
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		view.Failure(`:-\`, args[0]+" is not a positive number")
+		fmt.Println()
+		os.Exit(1)
+	}
+	return n
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+
+	viper.SetDefault("undo.limit", 100)
+}
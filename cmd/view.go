@@ -0,0 +1,294 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var viewPersonal bool
+
+// viewName is the form of the names views may be saved under. Names are lowercase because the config file does not
+// tell case apart.
+var viewName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// viewCmd represents the view command
+var viewCmd = &cobra.Command{
+	Use:   "view <name> [query]",
+	Short: "Run a saved query",
+	DisableFlagsInUseLine: true,
+	Long: `
+Lists the items matching a query saved with 'cb view save', as 'cb list'
+would. Any further query given is combined with the saved one, so that only
+items matching both are listed.
+
+Views are saved either in the book, where they are shared with everyone
+working on it, or with --personal in your config file, where they are yours
+alone. A personal view hides a view with the same name in the book.
+
+Examples:
+
+   cb view save standup is:open board:#team
+   cb view save --personal mine is:open starred
+   cb view standup
+   cb view standup starred --sort priority
+   cb view list
+   cb view delete standup
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		saved, ok := findView(args[0])
+		if !ok {
+			view.Failure(`:-\`, "No view named "+args[0])
+			fmt.Println()
+			os.Exit(1)
+		}
+		if extra := data.JoinQueryArgs(args[1:]); extra != "" && strings.TrimSpace(saved) != "" {
+			saved = "(" + saved + ") " + extra
+		} else if extra != "" {
+			saved = extra
+		}
+
+		query := parseItemQuery(saved)
+		newRenderer().PrintSections(groupedSections(matching(query, itemstore.ActiveItems), view.GroupByBoard, view.ById))
+		if textOutput() {
+			fmt.Println()
+		}
+	},
+}
+
+// viewSaveCmd represents the view save command
+var viewSaveCmd = &cobra.Command{
+	Use:   "save <name> <query>",
+	Short: "Save a query as a view",
+	DisableFlagsInUseLine: true,
+	Long: `
+Saves a query under a name, replacing any view saved with that name before.
+The view is saved in the book unless --personal is given. Quote the query to
+use or, and, not or parentheses in it, as in 'cb list'.
+
+Saving a view changes no items, so it cannot be undone with 'cb undo'; delete
+the view or save it again instead.
+
+Examples:
+
+   cb view save standup is:open board:#team
+   cb view save blockers 'is:blocked or (is:open starred)'
+`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if !viewName.MatchString(name) || isViewSubcommand(name) {
+			view.Failure(`:-\`, "Views must be named with lowercase letters, digits, - and _, and not save, list, "+
+				"delete or help, unlike "+name)
+			fmt.Println()
+			os.Exit(1)
+		}
+		query := data.JoinQueryArgs(args[1:])
+		if query == "" {
+			view.Failure(`:-\`, "A view needs a query; use 'cb list' to list every item")
+			fmt.Println()
+			os.Exit(1)
+		}
+		parseItemQuery(query)
+
+		_, replaced := personalViews()[name]
+		if viewPersonal {
+			err := updateConfig(func(settings map[string]interface{}) {
+				views, _ := settings["views"].(map[string]interface{})
+				if views == nil {
+					views = make(map[string]interface{})
+				}
+				views[name] = query
+				settings["views"] = views
+			})
+			if err != nil {
+				view.Failure(`:-O`, "Could not write the config file because:\n\t"+err.Error())
+				fmt.Println()
+				os.Exit(1)
+			}
+		} else {
+			_, replaced = itemstore.View(name)
+			itemstore.SaveView(name, query)
+			rebaseViews()
+		}
+
+		if replaced {
+			view.Success(`:-)`, "Replaced view "+name)
+		} else {
+			view.Success(`:-)`, "Saved view "+name)
+		}
+		fmt.Println()
+	},
+}
+
+// viewListCmd represents the view list command
+var viewListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"l"},
+	Short:   "List saved views",
+	DisableFlagsInUseLine: true,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		type saved struct {
+			name, scope, query string
+		}
+		var views []saved
+		for name, query := range personalViews() {
+			views = append(views, saved{name, "personal", query})
+		}
+		for _, name := range itemstore.Views() {
+			query, _ := itemstore.View(name)
+			views = append(views, saved{name, "book", query})
+		}
+		if len(views) == 0 {
+			view.Success(`:-|`, "No views saved")
+			fmt.Println()
+			return
+		}
+		sort.Slice(views, func(i, j int) bool {
+			if views[i].name != views[j].name {
+				return views[i].name < views[j].name
+			}
+			return views[i].scope == "personal"
+		})
+
+		width := 0
+		for _, v := range views {
+			if len(v.name) > width {
+				width = len(v.name)
+			}
+		}
+		heading := newRenderer().Theme().Heading
+		fmt.Println()
+		for i, v := range views {
+			query := v.query
+			if i > 0 && views[i-1].name == v.name {
+				query += "  (hidden by the personal view)"
+			}
+			fmt.Printf("  %s  %-8s  %s\n", heading.Sprint(fmt.Sprintf("%-*s", width, v.name)), v.scope, query)
+		}
+		fmt.Println()
+	},
+}
+
+// viewDeleteCmd represents the view delete command
+var viewDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a saved view",
+	DisableFlagsInUseLine: true,
+	Long: `
+Deletes a view from the book, or from your config file with --personal.
+
+Deleting a view changes no items, so it cannot be undone with 'cb undo'; save
+the view again instead.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if viewPersonal {
+			if _, ok := personalViews()[name]; !ok {
+				view.Failure(`:-\`, "No personal view named "+name)
+				fmt.Println()
+				os.Exit(1)
+			}
+			err := updateConfig(func(settings map[string]interface{}) {
+				if views, ok := settings["views"].(map[string]interface{}); ok {
+					delete(views, name)
+				}
+			})
+			if err != nil {
+				view.Failure(`:-O`, "Could not write the config file because:\n\t"+err.Error())
+				fmt.Println()
+				os.Exit(1)
+			}
+		} else {
+			if !itemstore.DeleteView(name) {
+				hint := ""
+				if _, ok := personalViews()[name]; ok {
+					hint = "; use --personal to delete your own"
+				}
+				view.Failure(`:-\`, "No view named "+name+" in the book"+hint)
+				fmt.Println()
+				os.Exit(1)
+			}
+			rebaseViews()
+		}
+		view.Success(`:-)`, "Deleted view "+name)
+		fmt.Println()
+	},
+}
+
+// personalViews returns the queries of the views saved in the config file, by name.
+func personalViews() map[string]string {
+	return viper.GetStringMapString("views")
+}
+
+// findView returns the query of the named view, preferring a personal view to one saved in the book.
+func findView(name string) (string, bool) {
+	if query, ok := personalViews()[name]; ok {
+		return query, true
+	}
+	return itemstore.View(name)
+}
+
+// isViewSubcommand reports whether name would be taken for one of the subcommands of view rather than a view.
+func isViewSubcommand(name string) bool {
+	for _, sub := range viewCmd.Commands() {
+		if sub.Name() == name || sub.HasAlias(name) {
+			return true
+		}
+	}
+	return name == "help"
+}
+
+// rebaseViews makes a change to the views in the book part of its event log, since views are kept in snapshots
+// rather than recorded as events.
+func rebaseViews() {
+	if err := rebaseEvents(cbPath, itemstore); err != nil {
+		view.Failure(`:-O`, "Could not update the event log because:\n\t"+err.Error())
+		fmt.Println()
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(viewCmd)
+	viewCmd.AddCommand(viewSaveCmd)
+	viewCmd.AddCommand(viewListCmd)
+	viewCmd.AddCommand(viewDeleteCmd)
+	addListingFlags(viewCmd)
+	viewCmd.Long += queryHelp
+
+	viewSaveCmd.Flags().BoolVar(&viewPersonal, "personal", false, "save the view in your config file instead of the book")
+	viewDeleteCmd.Flags().BoolVar(&viewPersonal, "personal", false, "delete the view from your config file instead of the book")
+}
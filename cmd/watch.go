@@ -0,0 +1,230 @@
+// Copyright © 2018 K. Alex Mills <k.alex.mills@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/fsnotify/fsnotify"
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var watchPoll bool
+var watchInterval time.Duration
+
+// watchViews are the views cb watch can show, by name. Each shows the items in the store it is given, which is read
+// afresh whenever the book changes.
+var watchViews = map[string]func(*view.Renderer, *data.Repo){
+	"list": func(r *view.Renderer, store *data.Repo) {
+		r.PrintSections(view.GroupedSections(store, store.ActiveItems, itemGrouping(view.GroupByBoard),
+			itemOrder(view.ById)))
+	},
+	"timeline": func(r *view.Renderer, store *data.Repo) {
+		r.PrintSections(view.GroupedSections(store, store.ActiveItems, itemGrouping(view.GroupByDay),
+			itemOrder(view.ByCreated)))
+	},
+	"kanban": func(r *view.Renderer, store *data.Repo) {
+		r.PrintColumns(func() []view.Section { return statusSections(store) })
+	},
+}
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:       "watch [list|timeline|kanban]",
+	Short:     "Keep a view up to date as the book changes",
+	DisableFlagsInUseLine: true,
+	Long: `
+Shows the list, timeline or kanban view, list by default, and shows it again
+whenever the book changes on disk, for instance when a teammate adds a task
+or a git pull brings in new work. Items which changed since the view was last
+shown are highlighted. Press ctrl-c to stop watching.
+
+Changes are noticed through filesystem notifications where they are
+available, or else by checking the book every --interval. Use --poll to
+always check, e.g. on network filesystems which do not send notifications.
+
+Examples:
+
+   cb watch
+   cb watch kanban
+   cb watch timeline --poll --interval 5s
+`,
+	ValidArgs: []string{"list", "timeline", "kanban"},
+	Args:      cobra.MaximumNArgs(1),
+	// Nothing is changed while watching, so there is nothing to save.
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		name := "list"
+		if len(args) > 0 {
+			name = args[0]
+		}
+		show, ok := watchViews[name]
+		if !ok {
+			view.Failure(`:-\`, "Can only watch list, timeline or kanban, not "+name)
+			fmt.Println()
+			os.Exit(1)
+		}
+		if watchInterval <= 0 {
+			view.Failure(`:-\`, "--interval must be positive, not "+watchInterval.String())
+			fmt.Println()
+			os.Exit(1)
+		}
+
+		changes := make(chan struct{}, 1)
+		if watchPoll || !notifyChanges(cbPath, changes) {
+			go pollChanges(cbPath, watchInterval, changes)
+		}
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+		var previous map[string]string
+		for {
+			previous = showWatched(show, previous)
+			select {
+			case <-changes:
+				// Saving a book takes several filesystem operations; wait for them all before reading it.
+				time.Sleep(100 * time.Millisecond)
+				select {
+				case <-changes:
+				default:
+				}
+			case <-interrupt:
+				fmt.Println()
+				return
+			}
+		}
+	},
+}
+
+// showWatched reads the book again and shows it, highlighting the items whose fingerprints differ from previous. It
+// returns the fingerprints of the items shown, or previous if the book could not be read.
+func showWatched(show func(*view.Renderer, *data.Repo), previous map[string]string) map[string]string {
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		fmt.Fprint(color.Output, "\033[H\033[2J")
+	}
+	store, err := openCollabbook(cbPath)
+	if err != nil {
+		view.Failure(":-O", "Could not read "+cbPath+" because:\n\t"+err.Error())
+		fmt.Println()
+		return previous
+	}
+
+	current := make(map[string]string, store.Len())
+	changed := make(map[uint64]bool)
+	for _, it := range store.Items() {
+		current[it.Uid] = fingerprint(store, it)
+		if previous != nil && previous[it.Uid] != current[it.Uid] {
+			changed[it.Id] = true
+		}
+	}
+
+	r := rendererFor(store)
+	r.SetChanged(changed)
+	show(r, store)
+	if textOutput() {
+		fmt.Printf("\n\n  Watching %s, last read at %s. Press ctrl-c to stop.\n", cbPath, time.Now().Format("15:04:05"))
+	}
+	return current
+}
+
+// fingerprint describes everything shown about an item, so that items which look different can be highlighted.
+func fingerprint(store *data.Repo, it *data.Item) string {
+	boards := store.BoardsOf(it.Id)
+	sort.Strings(boards)
+	return fmt.Sprintf("%d %t %s %t %s %s", it.Id, it.IsTask(), it.State(), it.IsStarred(),
+		strings.Join(boards, ","), it.Desc)
+}
+
+// notifyChanges sends on changes whenever the file at path is written, returning false if filesystem notifications
+// are not available. The directory holding the file is watched rather than the file itself, since saving a book
+// replaces the file with a new one.
+func notifyChanges(path string, changes chan<- struct{}) bool {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return false
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return false
+	}
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == filepath.Clean(path) {
+					signalChange(changes)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				// Notifications may have been lost, so look again to be sure.
+				signalChange(changes)
+			}
+		}
+	}()
+	return true
+}
+
+// pollChanges sends on changes whenever the size or modification time of the file at path changes, checking every
+// interval.
+func pollChanges(path string, interval time.Duration, changes chan<- struct{}) {
+	last, _ := os.Stat(path)
+	for range time.Tick(interval) {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil && last == nil:
+		case err != nil || last == nil || info.Size() != last.Size() || !info.ModTime().Equal(last.ModTime()):
+			signalChange(changes)
+		}
+		last = info
+	}
+}
+
+// signalChange sends on changes without waiting, since one pending change is as good as many.
+func signalChange(changes chan<- struct{}) {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().BoolVar(&watchPoll, "poll", false, "check the book every --interval instead of relying on notifications")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 2*time.Second, "how often to check the book when polling")
+}
@@ -0,0 +1,220 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Op names the kind of mutation which produced a Change.
+type Op string
+
+const (
+	OpCreate Op = "create"
+	OpCheck  Op = "check"
+	OpStar   Op = "star"
+	OpDelete Op = "delete"
+	OpBoard  Op = "board"
+	OpMove   Op = "move"
+	OpEdit   Op = "edit"
+	OpState  Op = "state"
+)
+
+// ItemState is a self-contained snapshot of an item and the boards it belongs to.
+type ItemState struct {
+	Id           uint64
+	Uid          string
+	Task         bool
+	Complete     bool
+	State        State // of tasks; recorded changes from before tasks had states only have Complete
+	Starred      bool
+	CreatedUTC   time.Time
+	CompletedUTC time.Time
+	ReopenedUTC  time.Time
+	Desc         string
+	Boards       []string
+}
+
+// Change records the effect of a single mutation on a single item. Before is nil for items which were created, and
+// After is nil for items which were deleted.
+type Change struct {
+	Op     Op
+	Before *ItemState
+	After  *ItemState
+}
+
+// Event is a Change as recorded in a book's event log, stamped with when it was made and by whom.
+type Event struct {
+	Time   time.Time
+	Author string
+	Change
+}
+
+// Invert returns the change which undoes c.
+func (c Change) Invert() Change {
+	return Change{c.Op, c.After, c.Before}
+}
+
+func (c Change) String() string {
+	state := c.After
+	if state == nil {
+		state = c.Before
+	}
+	if state == nil {
+		return string(c.Op)
+	}
+	kind := "note"
+	if state.Task {
+		kind = "task"
+	}
+
+	switch {
+	case c.Before == nil:
+		return fmt.Sprintf("created %s %d: %s", kind, state.Id, state.Desc)
+	case c.After == nil:
+		return fmt.Sprintf("deleted %s %d: %s", kind, state.Id, state.Desc)
+	case c.Before.Complete != c.After.Complete && c.After.Complete:
+		return fmt.Sprintf("checked task %d", state.Id)
+	case c.Before.Complete != c.After.Complete && c.After.State == Todo:
+		return fmt.Sprintf("unchecked task %d", state.Id)
+	case c.Before.State != c.After.State && c.After.State == InProgress:
+		return fmt.Sprintf("began task %d", state.Id)
+	case c.Before.State != c.After.State && c.After.State == Blocked:
+		return fmt.Sprintf("blocked task %d", state.Id)
+	case c.Before.State != c.After.State && c.After.State == Cancelled:
+		return fmt.Sprintf("cancelled task %d", state.Id)
+	case c.Before.State != c.After.State:
+		return fmt.Sprintf("reopened task %d", state.Id)
+	case c.Before.Starred != c.After.Starred && c.After.Starred:
+		return fmt.Sprintf("starred %s %d", kind, state.Id)
+	case c.Before.Starred != c.After.Starred:
+		return fmt.Sprintf("unstarred %s %d", kind, state.Id)
+	case c.Before.Desc != c.After.Desc:
+		return fmt.Sprintf("edited %s %d: %s", kind, state.Id, state.Desc)
+	}
+	return fmt.Sprintf("moved %s %d to %s", kind, state.Id, strings.Join(state.Boards, ", "))
+}
+
+// RecordChanges starts recording the changes made to the store, for TakeChanges to return. Repos record nothing until
+// it is called, so that those whose changes are never taken do not grow without bound.
+func (store *Repo) RecordChanges() {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.recording = true
+}
+
+// TakeChanges returns every change made to the store since TakeChanges was last called, in the order they were made.
+// Changes made by UnmarshalText and Repair, and those made before RecordChanges was called, are not included.
+func (store *Repo) TakeChanges() []Change {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	result := store.changes
+	store.changes = nil
+	return result
+}
+
+// Apply sets the item touched by change to its After state, creating or deleting it as needed, and records the
+// result under the change's Op. Items are matched by Uid; recreated items get their old display number back unless
+// another item has taken it since.
+func (store *Repo) Apply(change Change) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	target := change.After
+	uid := ""
+	if target != nil {
+		uid = target.Uid
+	} else if change.Before != nil {
+		uid = change.Before.Uid
+	}
+
+	var existing *Item
+	for _, it := range store.items {
+		if it != nil && it.Uid == uid {
+			existing = it
+			break
+		}
+	}
+
+	if target == nil {
+		if existing != nil {
+			before := store.state(existing.Id)
+			delete(store.items, existing.Id)
+			for _, board := range store.boards {
+				delete(board, existing.Id)
+			}
+			store.record(change.Op, before, existing.Id)
+		}
+		return
+	}
+
+	var before *ItemState
+	if existing != nil {
+		before = store.state(existing.Id)
+	} else {
+		id := target.Id
+		if _, taken := store.items[id]; taken {
+			id = store.nextId
+		}
+		existing = &Item{Id: id}
+		store.items[id] = existing
+		store.nextId = max(id+1, store.nextId)
+	}
+	existing.Uid = target.Uid
+	existing.flags = 0
+	if target.Task {
+		existing.flags = taskFlag
+		state := target.State
+		if target.Complete {
+			state = Done
+		} else if state == Done {
+			state = Todo
+		}
+		existing.SetState(state)
+	}
+	existing.SetStarred(target.Starred)
+	existing.CreatedUTC = target.CreatedUTC
+	existing.CompletedUTC = target.CompletedUTC
+	existing.ReopenedUTC = target.ReopenedUTC
+	existing.Desc = target.Desc
+
+	for _, board := range store.boards {
+		delete(board, existing.Id)
+	}
+	for _, name := range target.Boards {
+		store.addItemToBoard(existing.Id, name)
+	}
+	store.record(change.Op, before, existing.Id)
+}
+
+// state returns a snapshot of the item with the given id and its boards, or nil if there is no such item.
+func (store *Repo) state(id uint64) *ItemState {
+	it := store.items[id]
+	if it == nil {
+		return nil
+	}
+	result := &ItemState{
+		Id:           it.Id,
+		Uid:          it.Uid,
+		Task:         it.IsTask(),
+		Complete:     it.IsComplete(),
+		State:        it.State(),
+		Starred:      it.IsStarred(),
+		CreatedUTC:   it.CreatedUTC,
+		CompletedUTC: it.CompletedUTC,
+		ReopenedUTC:  it.ReopenedUTC,
+		Desc:         it.Desc,
+		Boards:       store.boardsOf(id),
+	}
+	return result
+}
+
+// record notes that the item with the given id was changed by op from its before state to its current state.
+func (store *Repo) record(op Op, before *ItemState, id uint64) {
+	if !store.recording {
+		return
+	}
+	store.changes = append(store.changes, Change{op, before, store.state(id)})
+}
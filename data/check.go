@@ -0,0 +1,150 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ProblemKind classifies the inconsistencies which Check can find in a Repo.
+type ProblemKind int
+
+const (
+	DanglingReference ProblemKind = iota // a board refers to an item which does not exist
+	EmptyItem                            // an id is present in the Repo without an item
+	OrphanedItem                         // an item is not on any board
+	DuplicateUid                         // two items share the same Uid
+)
+
+// Problem is a single inconsistency found by Check.
+type Problem struct {
+	Kind  ProblemKind
+	Id    uint64 // the item at fault
+	Other uint64 // for DuplicateUid, the item sharing Id's Uid
+	Board string // for DanglingReference, the board holding the reference
+	Uid   string // for DuplicateUid, the shared Uid; for DanglingReference, the Uid referred to, if the board used one
+}
+
+func (p Problem) String() string {
+	switch p.Kind {
+	case DanglingReference:
+		if p.Uid != "" {
+			return fmt.Sprintf("board %q refers to missing item %s", p.Board, p.Uid)
+		}
+		return fmt.Sprintf("board %q refers to missing item %d", p.Board, p.Id)
+	case EmptyItem:
+		return fmt.Sprintf("item %d is empty", p.Id)
+	case OrphanedItem:
+		return fmt.Sprintf("item %d is not on any board", p.Id)
+	case DuplicateUid:
+		return fmt.Sprintf("items %d and %d share unique id %s", p.Other, p.Id, p.Uid)
+	}
+	return fmt.Sprintf("unknown problem with item %d", p.Id)
+}
+
+// Check looks for inconsistencies between the items and boards of the store, returning them ordered by item.
+func (store *Repo) Check() []Problem {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return store.check()
+}
+
+// Repair fixes every problem found by Check, returning the problems it fixed. Dangling references and empty items are
+// removed, orphaned items are re-homed to DefaultBoard, and items sharing a Uid are either merged, when they are exact
+// copies, or given a fresh Uid.
+func (store *Repo) Repair() []Problem {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	problems := store.check()
+	for _, p := range problems {
+		switch p.Kind {
+		case DanglingReference:
+			if p.Uid != "" {
+				delete(store.missing, p.Board)
+			} else {
+				delete(store.boards[p.Board], p.Id)
+			}
+		case EmptyItem:
+			delete(store.items, p.Id)
+			for _, board := range store.boards {
+				delete(board, p.Id)
+			}
+		case OrphanedItem:
+			store.addItemToBoard(p.Id, DefaultBoard)
+		case DuplicateUid:
+			store.repairDuplicate(p.Other, p.Id)
+		}
+	}
+	return problems
+}
+
+func (store *Repo) check() []Problem {
+	var result []Problem
+
+	onBoard := make(map[uint64]bool, len(store.items))
+	for name, board := range store.boards {
+		for id := range board {
+			onBoard[id] = true
+			if _, ok := store.items[id]; !ok {
+				result = append(result, Problem{Kind: DanglingReference, Id: id, Board: name})
+			}
+		}
+	}
+	for name, uids := range store.missing {
+		for _, uid := range uids {
+			result = append(result, Problem{Kind: DanglingReference, Board: name, Uid: uid})
+		}
+	}
+
+	byUid := make(map[string]uint64, len(store.items))
+	for _, id := range store.sortedIds() {
+		it := store.items[id]
+		if it == nil {
+			result = append(result, Problem{Kind: EmptyItem, Id: id})
+			continue
+		}
+		if !onBoard[id] {
+			result = append(result, Problem{Kind: OrphanedItem, Id: id})
+		}
+		if other, ok := byUid[it.Uid]; ok {
+			result = append(result, Problem{Kind: DuplicateUid, Id: id, Other: other, Uid: it.Uid})
+		} else {
+			byUid[it.Uid] = id
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Id != result[j].Id {
+			return result[i].Id < result[j].Id
+		}
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		if result[i].Board != result[j].Board {
+			return result[i].Board < result[j].Board
+		}
+		return result[i].Uid < result[j].Uid
+	})
+	return result
+}
+
+// repairDuplicate resolves two items sharing a Uid. An exact copy is merged into the original, keeping the boards of
+// both; anything else is a distinct item and is given a Uid of its own.
+func (store *Repo) repairDuplicate(original, duplicate uint64) {
+	orig, dup := store.items[original], store.items[duplicate]
+	if orig == nil || dup == nil {
+		return
+	}
+	if orig.flags != dup.flags || !orig.CreatedUTC.Equal(dup.CreatedUTC) || orig.Desc != dup.Desc {
+		dup.Uid = newUid(dup.CreatedUTC)
+		return
+	}
+	for _, board := range store.boards {
+		if board[duplicate] {
+			delete(board, duplicate)
+			board[original] = true
+		}
+	}
+	delete(store.items, duplicate)
+}
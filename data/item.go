@@ -1,18 +1,34 @@
 package data
 
-import "time"
+import (
+	"crypto/rand"
+	"strings"
+	"time"
 
+	"github.com/oklog/ulid"
+)
+
+// Item is a single note or task. Uid identifies the item across every copy of a book, while Id is the short display
+// number shown to users; display numbers are only unique within a single Repo and may be reassigned when books merge.
 type Item struct {
-	Id         uint64
-	flags      byte
-	CreatedUTC time.Time
-	Desc       string
+	Id           uint64
+	Uid          string
+	flags        byte
+	CreatedUTC   time.Time
+	CompletedUTC time.Time // when the task was last checked; zero unless it is complete
+	ReopenedUTC  time.Time // when the task was last unchecked; zero if it never has been
+	Desc         string
 }
 
 const (
 	taskFlag = 1 << iota
 	starFlag
 	completeFlag
+	progressFlag
+	blockedFlag
+	cancelledFlag
+
+	stateFlags = completeFlag | progressFlag | blockedFlag | cancelledFlag
 )
 
 func setFlag(flag *byte, mask byte, value bool) {
@@ -32,7 +48,7 @@ func (it *Item) IsStarred() bool {
 }
 
 func (it *Item) IsComplete() bool {
-	return it.IsTask() && (it.flags & (completeFlag)) > 0
+	return it.IsTask() && (it.flags&(completeFlag)) > 0
 }
 
 func (it *Item) SetStarred(value bool) {
@@ -40,5 +56,71 @@ func (it *Item) SetStarred(value bool) {
 }
 
 func (it *Item) SetComplete(value bool) {
-	setFlag(&it.flags, completeFlag, value)
+	if value {
+		it.SetState(Done)
+	} else {
+		it.SetState(Todo)
+	}
+}
+
+// State returns where a task stands. Notes are always Todo.
+func (it *Item) State() State {
+	if !it.IsTask() {
+		return Todo
+	}
+	switch {
+	case it.flags&completeFlag > 0:
+		return Done
+	case it.flags&progressFlag > 0:
+		return InProgress
+	case it.flags&blockedFlag > 0:
+		return Blocked
+	case it.flags&cancelledFlag > 0:
+		return Cancelled
+	}
+	return Todo
+}
+
+func (it *Item) SetState(state State) {
+	it.flags &^= stateFlags
+	switch state {
+	case InProgress:
+		it.flags |= progressFlag
+	case Blocked:
+		it.flags |= blockedFlag
+	case Done:
+		it.flags |= completeFlag
+	case Cancelled:
+		it.flags |= cancelledFlag
+	}
+}
+
+// Matches reports whether the item's description contains every one of terms, ignoring case.
+func (it *Item) Matches(terms ...string) bool {
+	desc := strings.ToLower(it.Desc)
+	for _, term := range terms {
+		if !strings.Contains(desc, strings.ToLower(term)) {
+			return false
+		}
+	}
+	return true
+}
+
+// newUid returns a fresh ULID for an item created at the given time. Times outside the range a ULID can hold, which
+// only damaged books contain, are clamped to it.
+func newUid(created time.Time) string {
+	ms := ulid.Timestamp(created)
+	switch {
+	case created.Before(time.Unix(0, 0)):
+		ms = 0
+	case ms > ulid.MaxTime():
+		ms = ulid.MaxTime()
+	}
+	return ulid.MustNew(ms, rand.Reader).String()
+}
+
+// IsUid reports whether ref is formatted as a ULID, and so refers to an item by Uid rather than display number.
+func IsUid(ref string) bool {
+	_, err := ulid.ParseStrict(strings.ToUpper(ref))
+	return err == nil
 }
@@ -0,0 +1,364 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid"
+)
+
+const (
+	itemSeparator  = "---"
+	boardSeparator = "---"
+	sectionEnd     = "====="
+)
+
+// escapeLine protects a line of free text, such as a description or a board name, which would otherwise be read as a
+// separator by putting a backslash in front of it. Lines ending in a carriage return, which would be lost when the book
+// is read, get a backslash after it. Lines which are already escaped get another backslash, so that unescapeLine
+// always gives back the original text.
+func escapeLine(text string) string {
+	if isSeparatorLike(text) {
+		text = `\` + text
+	}
+	if endsInReturn(text) {
+		text += `\`
+	}
+	return text
+}
+
+// unescapeLine undoes escapeLine.
+func unescapeLine(text string) string {
+	if strings.HasPrefix(text, `\`) && isSeparatorLike(text[1:]) {
+		text = text[1:]
+	}
+	if strings.HasSuffix(text, `\`) && endsInReturn(text[:len(text)-1]) {
+		text = text[:len(text)-1]
+	}
+	return text
+}
+
+// isSeparatorLike reports whether text is a separator, possibly after some backslashes.
+func isSeparatorLike(text string) bool {
+	text = strings.TrimLeft(text, `\`)
+	return text == itemSeparator || text == boardSeparator || text == sectionEnd
+}
+
+// endsInReturn reports whether text ends in a carriage return, possibly followed by some backslashes.
+func endsInReturn(text string) bool {
+	return strings.HasSuffix(strings.TrimRight(text, `\`), "\r")
+}
+
+// ParseError describes a single problem found while reading a book, with enough detail to find and repair it by hand.
+type ParseError struct {
+	Line  int    // 1-based line number of the offending text
+	Field string // name of the field which was being read
+	Text  string // the offending text
+	Err   error  // what was wrong with it
+}
+
+func (err *ParseError) Error() string {
+	if err.Text == "" {
+		return fmt.Sprintf("line %d: %s: %s", err.Line, err.Field, err.Err)
+	}
+	return fmt.Sprintf("line %d: %s %q: %s", err.Line, err.Field, err.Text, err.Err)
+}
+
+// ParseErrors is every ParseError found in a book, in the order in which they occur.
+type ParseErrors []*ParseError
+
+func (errs ParseErrors) Error() string {
+	switch len(errs) {
+	case 0:
+		return "no parse errors"
+	case 1:
+		return errs[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more)", errs[0].Error(), len(errs)-1)
+}
+
+type UnexpectedEndOfInput struct{}
+
+func (err *UnexpectedEndOfInput) Error() string {
+	return fmt.Sprintf("Unexpected end of input")
+}
+
+type unexpectedValue struct {
+	want string
+}
+
+func (err *unexpectedValue) Error() string {
+	return "expected " + err.want
+}
+
+// parser reads a book line by line, collecting errors rather than stopping at the first. Items which cannot be read
+// are skipped up to the next item separator so that the rest of the book is still loaded.
+type parser struct {
+	s      *bufio.Scanner
+	line   int
+	unread bool // when true, the current line is returned again by the next call to next
+	errs   ParseErrors
+}
+
+func newParser(text []byte) *parser {
+	return &parser{s: bufio.NewScanner(bytes.NewReader(text))}
+}
+
+func (p *parser) next() (string, bool) {
+	if p.unread {
+		p.unread = false
+		return p.s.Text(), true
+	}
+	if !p.s.Scan() {
+		if err := p.s.Err(); err != nil {
+			p.fail("line", "", err)
+		}
+		return "", false
+	}
+	p.line += 1
+	return p.s.Text(), true
+}
+
+func (p *parser) fail(field, text string, err error) *ParseError {
+	result := &ParseError{p.line, field, text, err}
+	p.errs = append(p.errs, result)
+	return result
+}
+
+// field reads the next line as the named field of an item. Running into a separator counts as a missing field, and
+// the separator is left to be read again.
+func (p *parser) field(name string) (string, bool) {
+	tok, ok := p.next()
+	if !ok {
+		p.fail(name, "", &UnexpectedEndOfInput{})
+		return "", false
+	}
+	if tok == itemSeparator || tok == sectionEnd {
+		p.unread = true
+		p.fail(name, tok, &unexpectedValue{"a value for " + name})
+		return "", false
+	}
+	return tok, true
+}
+
+// flag reads the next line as a T/F flag.
+func (p *parser) flag(name string) (bool, bool) {
+	tok, ok := p.field(name)
+	if !ok {
+		return false, false
+	}
+	switch tok {
+	case "T":
+		return true, true
+	case "F":
+		return false, true
+	}
+	p.fail(name, tok, &unexpectedValue{"T or F"})
+	return false, false
+}
+
+// optionalTime parses tok as a time, or as the zero time if tok is "-".
+func (p *parser) optionalTime(name, tok string, t *time.Time) bool {
+	if tok == "-" {
+		*t = time.Time{}
+		return true
+	}
+	var err error
+	if *t, err = time.Parse(time.RFC3339, tok); err != nil {
+		p.fail(name, tok, err)
+		return false
+	}
+	return true
+}
+
+// items reads the item section of a book up to and including its end marker.
+func (p *parser) items() []*Item {
+	var result []*Item
+	for {
+		tok, ok := p.next()
+		if !ok {
+			p.fail("item list", "", &UnexpectedEndOfInput{})
+			return result
+		}
+		if tok == sectionEnd {
+			return result
+		}
+		if it, ok := p.item(tok); ok {
+			result = append(result, it)
+		} else if !p.skipItem() {
+			return result
+		}
+	}
+}
+
+// skipItem discards lines up to the end of the current item, returning false if the end of the item section was
+// reached instead.
+func (p *parser) skipItem() bool {
+	for {
+		tok, ok := p.next()
+		if !ok {
+			return false
+		}
+		if tok == itemSeparator {
+			return true
+		}
+		if tok == sectionEnd {
+			p.unread = true
+			return true
+		}
+	}
+}
+
+// item reads a single item whose Id line has already been read.
+func (p *parser) item(idLine string) (*Item, bool) {
+	it := new(Item)
+
+	// The Id line holds the display number, followed by the Uid in books written since Uids were introduced.
+	fields := strings.Fields(idLine)
+	if len(fields) == 0 || len(fields) > 2 {
+		p.fail("id", idLine, &unexpectedValue{"a display number and an optional unique id"})
+		return nil, false
+	}
+	var err error
+	if it.Id, err = strconv.ParseUint(fields[0], 10, 64); err != nil {
+		p.fail("id", fields[0], err)
+		return nil, false
+	}
+	if len(fields) > 1 {
+		uid, err := ulid.ParseStrict(strings.ToUpper(fields[1]))
+		if err != nil {
+			p.fail("unique id", fields[1], err)
+			return nil, false
+		}
+		it.Uid = uid.String()
+	}
+
+	kind, ok := p.field("kind")
+	if !ok {
+		return nil, false
+	}
+	switch kind {
+	case "T":
+		it.flags = taskFlag
+		letter, ok := p.field("state")
+		if !ok {
+			return nil, false
+		}
+		state, ok := stateOfLetter(letter)
+		if !ok {
+			p.fail("state", letter, &unexpectedValue{"T, F, P, B or C"})
+			return nil, false
+		}
+		it.SetState(state)
+	case "N":
+	default:
+		p.fail("kind", kind, &unexpectedValue{"T for a task or N for a note"})
+		return nil, false
+	}
+
+	starred, ok := p.flag("starred")
+	if !ok {
+		return nil, false
+	}
+	it.SetStarred(starred)
+
+	dates, ok := p.field("created")
+	if !ok {
+		return nil, false
+	}
+	// The date line holds the creation time, followed by the completion and reopening times of tasks which have them.
+	times := strings.Fields(dates)
+	if len(times) == 0 || len(times) > 3 {
+		p.fail("created", dates, &unexpectedValue{"a creation time and optional completion and reopening times"})
+		return nil, false
+	}
+	if it.CreatedUTC, err = time.Parse(time.RFC3339, times[0]); err != nil {
+		p.fail("created", times[0], err)
+		return nil, false
+	}
+	if len(times) > 1 && !p.optionalTime("completed", times[1], &it.CompletedUTC) {
+		return nil, false
+	}
+	if len(times) > 2 && !p.optionalTime("reopened", times[2], &it.ReopenedUTC) {
+		return nil, false
+	}
+
+	if it.Desc, ok = p.field("description"); !ok {
+		return nil, false
+	}
+	it.Desc = unescapeLine(it.Desc)
+
+	tok, ok := p.next()
+	if !ok {
+		p.fail("item separator", "", &UnexpectedEndOfInput{})
+		return nil, false
+	}
+	if tok != itemSeparator {
+		p.fail("item separator", tok, &unexpectedValue{strconv.Quote(itemSeparator)})
+		return nil, false
+	}
+	return it, true
+}
+
+// boards reads the board section of a book up to and including its end marker, if any, mapping each board name to
+// the item references it contains.
+func (p *parser) boards() map[string][]string {
+	result := make(map[string][]string)
+	for {
+		name, ok := p.next()
+		if !ok || name == sectionEnd {
+			return result
+		}
+		name = unescapeLine(name)
+		refs := make([]string, 0, 4)
+		for {
+			tok, ok := p.next()
+			if !ok || tok == boardSeparator {
+				break
+			}
+			if _, err := strconv.ParseUint(tok, 10, 64); err != nil && !IsUid(tok) {
+				p.fail("board entry", tok, &unexpectedValue{"a display number or unique id"})
+				continue
+			}
+			refs = append(refs, tok)
+		}
+		result[name] = append(result[name], refs...)
+	}
+}
+
+// views reads the view section at the end of a book, mapping the name of each view to its query. Books without views
+// have no view section.
+func (p *parser) views() map[string]string {
+	result := make(map[string]string)
+	for {
+		name, ok := p.next()
+		if !ok {
+			return result
+		}
+		query, ok := p.field("query")
+		if !ok {
+			p.skipView()
+			continue
+		}
+		if tok, ok := p.next(); ok && tok != itemSeparator {
+			p.fail("view separator", tok, &unexpectedValue{strconv.Quote(itemSeparator)})
+			p.skipView()
+			continue
+		}
+		result[unescapeLine(name)] = unescapeLine(query)
+	}
+}
+
+// skipView discards lines up to the end of the current view.
+func (p *parser) skipView() {
+	for {
+		tok, ok := p.next()
+		if !ok || tok == itemSeparator {
+			return
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package data
+
+import (
+	"bytes"
+	"testing"
+)
+
+// roundTrip marshals store and reads the result into a fresh Repo, failing the test on any error.
+func roundTrip(t *testing.T, store *Repo) *Repo {
+	t.Helper()
+	text, err := store.MarshalText()
+	if err != nil {
+		t.Fatalf("marshalling: %v", err)
+	}
+	result := NewRepo()
+	if err := result.UnmarshalText(text); err != nil {
+		t.Fatalf("reading back\n%s\nfailed: %v", text, err)
+	}
+	return result
+}
+
+func TestRoundTrip(t *testing.T) {
+	descs := []string{
+		"plain",
+		itemSeparator,
+		sectionEnd,
+		`\` + itemSeparator,
+		`\\` + sectionEnd,
+		`\`,
+		"--- not a separator",
+		"  ---",
+		"日本語 😀",
+		"ends in a return\r",
+		"\r\\",
+		"\r",
+	}
+
+	store := NewRepo()
+	for _, desc := range descs {
+		store.MakeTask(desc, "#tasks")
+		store.MakeNote(desc)
+	}
+	done := store.MakeTask("done", "#tasks", "@someone")
+	store.ToggleTaskIsComplete(done.Id)
+	store.SetTaskState(store.MakeTask("blocked").Id, Blocked)
+	store.ToggleItemIsStarred(done.Id)
+	store.SaveView("separator", itemSeparator)
+	store.SaveView("standup", "is:open board:#team")
+	store.SaveView(sectionEnd, "return\r")
+	store.MakeNote("on odd boards", itemSeparator, sectionEnd, "#return\r", `\`+itemSeparator)
+
+	loaded := roundTrip(t, store)
+	for _, want := range store.Items() {
+		got := loaded.Item(want.Id)
+		if got == nil {
+			t.Errorf("item %d %q was lost", want.Id, want.Desc)
+			continue
+		}
+		if got.Desc != want.Desc || got.Uid != want.Uid || got.State() != want.State() ||
+			got.IsStarred() != want.IsStarred() || got.IsTask() != want.IsTask() ||
+			!got.CreatedUTC.Equal(want.CreatedUTC) || !got.CompletedUTC.Equal(want.CompletedUTC) {
+			t.Errorf("item %d changed on the way through:\n got %+v\nwant %+v", want.Id, got, want)
+		}
+		if a, b := loaded.BoardsOf(want.Id), store.BoardsOf(want.Id); !equalStrings(a, b) {
+			t.Errorf("item %d is on %v, want %v", want.Id, a, b)
+		}
+	}
+	for _, name := range store.Views() {
+		want, _ := store.View(name)
+		if got, _ := loaded.View(name); got != want {
+			t.Errorf("view %s is %q, want %q", name, got, want)
+		}
+	}
+
+	first, _ := store.MarshalText()
+	second, _ := loaded.MarshalText()
+	if !bytes.Equal(first, second) {
+		t.Errorf("book changed on the way through:\n%s\nbecame\n%s", first, second)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name, book string
+		line       int
+		field      string
+	}{
+		{"bad kind", "0 01M3VB29M009KRAHFJKDQ0GGEV\nX\nF\n2026-10-01T09:00:00Z\nd\n---\n=====\n", 2, "kind"},
+		{"bad state", "0\nT\nQ\nF\n2026-10-01T09:00:00Z\nd\n---\n=====\n", 3, "state"},
+		{"bad time", "0\nN\nF\nyesterday\nd\n---\n=====\n", 4, "created"},
+		{"missing description", "0\nN\nF\n2026-10-01T09:00:00Z\n---\n=====\n", 5, "description"},
+		{"truncated", "0\nN\n", 2, "starred"},
+		{"bad board entry", "=====\n#b\nnonsense\n---\n", 3, "board entry"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := NewRepo().UnmarshalText([]byte(test.book))
+			errs, ok := err.(ParseErrors)
+			if !ok || len(errs) == 0 {
+				t.Fatalf("got %v, want ParseErrors", err)
+			}
+			if errs[0].Line != test.line || errs[0].Field != test.field {
+				t.Errorf("got %v, want an error in %s on line %d", errs[0], test.field, test.line)
+			}
+		})
+	}
+}
+
+// FuzzUnmarshalText checks that no book, however damaged, makes the parser panic, and that any book read without
+// errors is written back out in a form which reads back the same.
+func FuzzUnmarshalText(f *testing.F) {
+	f.Add([]byte("0 01M3VB29M009KRAHFJKDQ0GGEV\nT\nT\nF\n2026-10-01T09:00:00Z 2026-10-02T17:00:00Z\ntask 0\n---\n" +
+		"=====\nMy board\n01M3VB29M009KRAHFJKDQ0GGEV\n---\narchive\n---\n"))
+	f.Add([]byte("3\nN\nT\n2026-10-01T09:00:00Z - 2026-10-03T09:00:00Z\n\\---\n---\n=====\n#b\n3\n---\n=====\nv\nis:open\n---\n"))
+	f.Add([]byte("=====\n"))
+	f.Add([]byte(""))
+
+	f.Fuzz(func(t *testing.T, book []byte) {
+		store := NewRepo()
+		if err := store.UnmarshalText(book); err != nil {
+			return
+		}
+		first, err := store.MarshalText()
+		if err != nil {
+			t.Fatalf("marshalling: %v", err)
+		}
+		loaded := NewRepo()
+		if err := loaded.UnmarshalText(first); err != nil {
+			t.Fatalf("reading back\n%s\nfailed: %v", first, err)
+		}
+		second, _ := loaded.MarshalText()
+		if !bytes.Equal(first, second) {
+			t.Fatalf("book changed on the way through:\n%s\nbecame\n%s", first, second)
+		}
+	})
+}
+
+func TestDanglingUidReference(t *testing.T) {
+	book := "=====\n#b\n01M3VB29M009KRAHFJKDQ0GGEV\n---\n"
+	store := NewRepo()
+	if err := store.UnmarshalText([]byte(book)); err != nil {
+		t.Fatalf("reading: %v", err)
+	}
+
+	problems := store.Check()
+	if len(problems) != 1 || problems[0].Kind != DanglingReference || problems[0].Board != "#b" ||
+		problems[0].Uid != "01M3VB29M009KRAHFJKDQ0GGEV" {
+		t.Fatalf("got problems %v, want the reference from #b", problems)
+	}
+	if text, _ := store.MarshalText(); !bytes.Contains(text, []byte("#b\n01M3VB29M009KRAHFJKDQ0GGEV\n---\n")) {
+		t.Errorf("the reference was not written back:\n%s", text)
+	}
+
+	store.Repair()
+	if problems := store.Check(); len(problems) != 0 {
+		t.Errorf("got problems %v after repair, want none", problems)
+	}
+}
+
+func TestDanglingIdReference(t *testing.T) {
+	book := "0 01M3VB29M009KRAHFJKDQ0GGEV\nN\nF\n2026-10-01T09:00:00Z\nnote\n---\n=====\n#a\n0\n---\n#stale\n3\n---\n"
+	store := NewRepo()
+	if err := store.UnmarshalText([]byte(book)); err != nil {
+		t.Fatalf("reading: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		it := store.MakeTask("new", "#b")
+		if boards := store.BoardsOf(it.Id); len(boards) != 1 || boards[0] != "#b" {
+			t.Errorf("new item %d is on %v, want only #b", it.Id, boards)
+		}
+	}
+	if problems := store.Check(); len(problems) != 1 || problems[0].Id != 3 || problems[0].Board != "#stale" {
+		t.Errorf("got problems %v, want the reference to 3 from #stale", problems)
+	}
+}
@@ -0,0 +1,413 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is a filter over items, parsed from text such as
+//
+//	is:task is:open board:#release starred created:>2026-09-01 "flaky"
+//
+// by ParseQuery. Terms next to each other must all match; "or" between terms matches either, "not" or a leading "-"
+// inverts a term, and terms may be grouped in parentheses.
+type Query interface {
+	// Match reports whether an item on the given boards passes the filter.
+	Match(it *Item, boards []string) bool
+}
+
+// allQuery matches items matching every one of its terms, and so matches every item when it has none.
+type allQuery []Query
+
+func (q allQuery) Match(it *Item, boards []string) bool {
+	for _, term := range q {
+		if !term.Match(it, boards) {
+			return false
+		}
+	}
+	return true
+}
+
+// anyQuery matches items matching at least one of its terms.
+type anyQuery []Query
+
+func (q anyQuery) Match(it *Item, boards []string) bool {
+	for _, term := range q {
+		if term.Match(it, boards) {
+			return true
+		}
+	}
+	return false
+}
+
+type notQuery struct {
+	term Query
+}
+
+func (q notQuery) Match(it *Item, boards []string) bool {
+	return !q.term.Match(it, boards)
+}
+
+// textQuery matches items whose description contains its text, ignoring case.
+type textQuery string
+
+func (q textQuery) Match(it *Item, boards []string) bool {
+	return it.Matches(string(q))
+}
+
+// isQuery matches items of a kind, e.g. tasks or starred items, as named after is:.
+type isQuery func(it *Item) bool
+
+func (q isQuery) Match(it *Item, boards []string) bool {
+	return q(it)
+}
+
+// boardQuery matches items on a board, given its name without the # or @ it starts with.
+type boardQuery string
+
+func (q boardQuery) Match(it *Item, boards []string) bool {
+	for _, board := range boards {
+		if strings.EqualFold(strings.TrimLeft(board, "#@"), string(q)) {
+			return true
+		}
+	}
+	return false
+}
+
+// dateQuery compares the local day on which something happened to an item with a given day.
+type dateQuery struct {
+	when func(it *Item) time.Time
+	op   string
+	day  time.Time
+}
+
+func (q dateQuery) Match(it *Item, boards []string) bool {
+	t := q.when(it)
+	if t.IsZero() {
+		return false
+	}
+	t = t.Local()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	switch q.op {
+	case "<":
+		return day.Before(q.day)
+	case "<=":
+		return !day.After(q.day)
+	case ">":
+		return day.After(q.day)
+	case ">=":
+		return !day.Before(q.day)
+	}
+	return day.Equal(q.day)
+}
+
+// kinds are the values accepted after is:, besides the names of states.
+var kinds = map[string]func(it *Item) bool{
+	"task":    (*Item).IsTask,
+	"note":    func(it *Item) bool { return !it.IsTask() },
+	"starred": (*Item).IsStarred,
+	"open":    func(it *Item) bool { return it.IsTask() && !it.State().IsClosed() },
+	"closed":  func(it *Item) bool { return it.IsTask() && it.State().IsClosed() },
+}
+
+// dates are the fields which compare dates, and the time each reads from an item.
+var dates = map[string]func(it *Item) time.Time{
+	"created":   func(it *Item) time.Time { return it.CreatedUTC },
+	"completed": func(it *Item) time.Time { return it.CompletedUTC },
+}
+
+// QueryError describes what is wrong with a query, and where.
+type QueryError struct {
+	Query string // the whole query
+	Pos   int    // the index of the rune at which the problem was found
+	Msg   string
+}
+
+// Error explains the problem and points it out beneath the query.
+func (err *QueryError) Error() string {
+	return fmt.Sprintf("%s\n\t%s\n\t%s^", err.Msg, err.Query, strings.Repeat(" ", err.Pos))
+}
+
+// ParseQuery reads a query. An empty query matches every item.
+func ParseQuery(text string) (Query, error) {
+	p := &queryParser{text: text}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	q, err := p.parseAny()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok != nil {
+		return nil, p.errorAt(tok.pos, "unexpected %s", tok.raw)
+	}
+	return q, nil
+}
+
+// JoinQueryArgs turns the arguments given to a command into the text of a single query. The shell has already taken
+// the quotes off them, so each argument is read as a term of its own: or, and and not given alone are searched for as
+// words, a board whose name has spaces in it, as in board:"My board", gets its quotes back, and any other argument
+// with spaces in it is read as a query in its own right, in parentheses. The operators can therefore only be used
+// inside a quoted query, as in 'milk or eggs'.
+func JoinQueryArgs(args []string) string {
+	terms := make([]string, 0, len(args))
+	for _, arg := range args {
+		arg = strings.TrimSpace(arg)
+		p := &queryParser{text: arg}
+		if err := p.lex(); err != nil {
+			// Left as it is, so that ParseQuery reports the problem.
+			terms = append(terms, arg)
+			continue
+		}
+		switch {
+		case len(p.tokens) == 0:
+		case len(p.tokens) == 1 && isOperator(p.tokens[0]):
+			terms = append(terms, `"`+arg+`"`)
+		case len(p.tokens) == 1 || strings.IndexFunc(arg, unicode.IsSpace) < 0:
+			terms = append(terms, arg)
+		case isBoardName(p.tokens):
+			terms = append(terms, `board:"`+strings.TrimSpace(arg[len("board:"):])+`"`)
+		default:
+			terms = append(terms, "("+arg+")")
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+func isOperator(tok *token) bool {
+	return tok.keyword("or") || tok.keyword("and") || tok.keyword("not")
+}
+
+// isBoardName reports whether tokens are a board: field followed by nothing but plain words, which can only be the
+// name of a board with spaces in it.
+func isBoardName(tokens []*token) bool {
+	if tokens[0].kind != wordToken || tokens[0].field != "board" || tokens[0].quoted {
+		return false
+	}
+	for _, tok := range tokens[1:] {
+		if tok.kind != wordToken || tok.field != "" || tok.quoted || isOperator(tok) {
+			return false
+		}
+	}
+	return true
+}
+
+type tokenKind int
+
+const (
+	wordToken  tokenKind = iota // a word or quoted phrase, possibly after a field name and a colon
+	openToken                   // (
+	closeToken                  // )
+	notToken                    // - at the start of a term
+)
+
+type token struct {
+	kind   tokenKind
+	pos    int
+	raw    string // the token as written, for error messages
+	field  string // the name before the colon, if any
+	text   string // the word without its field or quotes
+	quoted bool
+}
+
+// keyword reports whether the token is the given unquoted word, ignoring case.
+func (tok *token) keyword(word string) bool {
+	return tok.kind == wordToken && !tok.quoted && tok.field == "" && strings.EqualFold(tok.text, word)
+}
+
+// queryParser turns the text of a query into tokens, and then parses the tokens by recursive descent following
+//
+//	any  = all { "or" all }
+//	all  = term { ["and"] term }
+//	term = ("not" | "-") term | "(" any ")" | word
+type queryParser struct {
+	text   string
+	tokens []*token
+	next   int
+}
+
+func (p *queryParser) errorAt(pos int, format string, args ...interface{}) error {
+	return &QueryError{Query: p.text, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) lex() error {
+	runes := []rune(p.text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			p.tokens = append(p.tokens, &token{kind: openToken, pos: i, raw: "("})
+			i++
+		case r == ')':
+			p.tokens = append(p.tokens, &token{kind: closeToken, pos: i, raw: ")"})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			p.tokens = append(p.tokens, &token{kind: notToken, pos: i, raw: "-"})
+			i++
+		default:
+			tok := &token{kind: wordToken, pos: i}
+			var text []rune
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				switch {
+				case runes[i] == '"':
+					end := i + 1
+					for end < len(runes) && runes[end] != '"' {
+						end++
+					}
+					if end == len(runes) {
+						return p.errorAt(i, "this quote is never closed")
+					}
+					text = append(text, runes[i+1:end]...)
+					tok.quoted = true
+					i = end + 1
+				case runes[i] == ':' && tok.field == "" && !tok.quoted && len(text) > 0:
+					tok.field = strings.ToLower(string(text))
+					text = text[:0]
+					i++
+				default:
+					text = append(text, runes[i])
+					i++
+				}
+			}
+			tok.raw = string(runes[tok.pos:i])
+			tok.text = string(text)
+			p.tokens = append(p.tokens, tok)
+		}
+	}
+	return nil
+}
+
+func (p *queryParser) peek() *token {
+	if p.next < len(p.tokens) {
+		return p.tokens[p.next]
+	}
+	return nil
+}
+
+func (p *queryParser) parseAny() (Query, error) {
+	if tok := p.peek(); tok != nil && tok.keyword("or") {
+		return nil, p.errorAt(tok.pos, "expected a term before %s", tok.raw)
+	}
+	first, err := p.parseAll()
+	if err != nil {
+		return nil, err
+	}
+	terms := anyQuery{first}
+	for tok := p.peek(); tok != nil && tok.keyword("or"); tok = p.peek() {
+		p.next++
+		if next := p.peek(); next == nil || next.kind == closeToken || next.keyword("or") {
+			return nil, p.errorAt(tok.pos, "expected a term after %s", tok.raw)
+		}
+		term, err := p.parseAll()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return terms, nil
+}
+
+func (p *queryParser) parseAll() (Query, error) {
+	var terms allQuery
+	for tok := p.peek(); tok != nil && tok.kind != closeToken && !tok.keyword("or"); tok = p.peek() {
+		if tok.keyword("and") {
+			p.next++
+			if next := p.peek(); next == nil || next.kind == closeToken || next.keyword("or") || next.keyword("and") {
+				return nil, p.errorAt(tok.pos, "expected a term after %s", tok.raw)
+			}
+			continue
+		}
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *queryParser) parseTerm() (Query, error) {
+	tok := p.peek()
+	p.next++
+	switch {
+	case tok.kind == notToken || tok.keyword("not"):
+		next := p.peek()
+		if next == nil || next.kind == closeToken || next.keyword("or") || next.keyword("and") {
+			return nil, p.errorAt(tok.pos, "expected a term after %s", tok.raw)
+		}
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		return notQuery{term}, nil
+	case tok.kind == openToken:
+		if next := p.peek(); next != nil && next.kind == closeToken {
+			return nil, p.errorAt(next.pos, "expected a term inside ( )")
+		}
+		q, err := p.parseAny()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next == nil || next.kind != closeToken {
+			return nil, p.errorAt(tok.pos, "this ( is never closed")
+		}
+		p.next++
+		return q, nil
+	case tok.kind == closeToken:
+		return nil, p.errorAt(tok.pos, "unexpected )")
+	case tok.keyword("starred"):
+		return isQuery(kinds["starred"]), nil
+	}
+	return p.parseWord(tok)
+}
+
+// parseWord reads a field and its value, or text to search for.
+func (p *queryParser) parseWord(tok *token) (Query, error) {
+	if tok.field == "" {
+		return textQuery(tok.text), nil
+	}
+	valuePos := tok.pos + len([]rune(tok.field)) + 1
+	if tok.text == "" {
+		return nil, p.errorAt(valuePos, "expected a value after %s:", tok.field)
+	}
+
+	switch tok.field {
+	case "is":
+		if kind, ok := kinds[strings.ToLower(tok.text)]; ok {
+			return isQuery(kind), nil
+		}
+		state, err := ParseState(tok.text)
+		if err != nil {
+			return nil, p.errorAt(valuePos, "unknown kind %q; expected task, note, starred, open, closed, todo, "+
+				"in-progress, blocked, done or cancelled", tok.text)
+		}
+		return isQuery(func(it *Item) bool { return it.IsTask() && it.State() == state }), nil
+	case "board":
+		return boardQuery(strings.TrimLeft(tok.text, "#@")), nil
+	case "created", "completed":
+		op := ""
+		for _, prefix := range []string{"<=", ">=", "<", ">", "="} {
+			if strings.HasPrefix(tok.text, prefix) {
+				op = prefix
+				break
+			}
+		}
+		day, err := time.ParseInLocation("2006-01-02", tok.text[len(op):], time.Local)
+		if err != nil {
+			return nil, p.errorAt(valuePos, "expected a date like 2006-01-02, optionally after <, <=, >, >= or =, "+
+				"not %q", tok.text)
+		}
+		return dateQuery{when: dates[tok.field], op: op, day: day}, nil
+	}
+	return nil, p.errorAt(tok.pos, "unknown field %q; expected is, board, created or completed", tok.field)
+}
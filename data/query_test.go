@@ -0,0 +1,163 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+// queryItems are the items queries are tested against, by name, with the boards each is on.
+func queryItems() (map[string]*Item, map[string][]string) {
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 12, 0, 0, 0, time.Local) }
+	items := map[string]*Item{
+		"milk":    {Desc: "Buy milk", flags: taskFlag, CreatedUTC: day(1)},
+		"eggs":    {Desc: "Buy eggs", flags: taskFlag, CreatedUTC: day(5)},
+		"release": {Desc: "Tag the release", flags: taskFlag | starFlag, CreatedUTC: day(10)},
+		"idea":    {Desc: "An idea or two", CreatedUTC: day(15)},
+	}
+	items["eggs"].SetState(Done)
+	items["eggs"].CompletedUTC = day(6)
+	items["release"].SetState(Blocked)
+	boards := map[string][]string{
+		"milk":    {"#shopping"},
+		"eggs":    {"#shopping"},
+		"release": {"#release", "My board"},
+		"idea":    {"My board"},
+	}
+	return items, boards
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"eggs", "idea", "milk", "release"}},
+		{"buy", []string{"eggs", "milk"}},
+		{"BUY MILK", []string{"milk"}},
+		{`"buy milk"`, []string{"milk"}},
+		{"milk or eggs", []string{"eggs", "milk"}},
+		{"buy and milk", []string{"milk"}},
+		{"buy not milk", []string{"eggs"}},
+		{"buy -milk", []string{"eggs"}},
+		{"not (milk or eggs)", []string{"idea", "release"}},
+		{"(milk or eggs) is:open", []string{"milk"}},
+		{"milk or eggs is:open", []string{"milk"}},
+		{"is:task", []string{"eggs", "milk", "release"}},
+		{"is:note", []string{"idea"}},
+		{"is:closed", []string{"eggs"}},
+		{"is:blocked", []string{"release"}},
+		{"starred", []string{"release"}},
+		{"is:starred", []string{"release"}},
+		{`"starred"`, nil},
+		{`"or"`, []string{"idea"}},
+		{"board:shopping", []string{"eggs", "milk"}},
+		{"board:#SHOPPING", []string{"eggs", "milk"}},
+		{`board:"My board"`, []string{"idea", "release"}},
+		{"created:2026-09-05", []string{"eggs"}},
+		{"created:>2026-09-05", []string{"idea", "release"}},
+		{"created:>=2026-09-05", []string{"eggs", "idea", "release"}},
+		{"created:<2026-09-05", []string{"milk"}},
+		{"created:<=2026-09-05", []string{"eggs", "milk"}},
+		{"completed:=2026-09-06", []string{"eggs"}},
+	}
+
+	items, boards := queryItems()
+	for _, test := range tests {
+		q, err := ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("%s: %v", test.query, err)
+			continue
+		}
+		var got []string
+		for _, name := range []string{"eggs", "idea", "milk", "release"} {
+			if q.Match(items[name], boards[name]) {
+				got = append(got, name)
+			}
+		}
+		if !equalStrings(got, test.want) {
+			t.Errorf("%s matched %v, want %v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{`milk "eggs`, 5, "this quote is never closed"},
+		{"or milk", 0, "expected a term before or"},
+		{"milk or", 5, "expected a term after or"},
+		{"milk or or eggs", 5, "expected a term after or"},
+		{"milk and", 5, "expected a term after and"},
+		{"milk not", 5, "expected a term after not"},
+		{"()", 1, "expected a term inside ( )"},
+		{"(milk", 0, "this ( is never closed"},
+		{"milk)", 4, "unexpected )"},
+		{"is:", 3, "expected a value after is:"},
+		{"milk is:someday", 8, `unknown kind "someday"; expected task, note, starred, open, closed, todo, ` +
+			"in-progress, blocked, done or cancelled"},
+		{"created:yesterday", 8, `expected a date like 2006-01-02, optionally after <, <=, >, >= or =, not "yesterday"`},
+		{"日本 due:tomorrow", 3, `unknown field "due"; expected is, board, created or completed`},
+	}
+	for _, test := range tests {
+		_, err := ParseQuery(test.query)
+		qerr, ok := err.(*QueryError)
+		if !ok {
+			t.Errorf("%s: got %v, want a QueryError", test.query, err)
+			continue
+		}
+		if qerr.Pos != test.pos || qerr.Msg != test.msg || qerr.Query != test.query {
+			t.Errorf("%s: got %q at %d, want %q at %d", test.query, qerr.Msg, qerr.Pos, test.msg, test.pos)
+		}
+	}
+}
+
+func TestJoinQueryArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"buy", "milk"}, "buy milk"},
+		{[]string{"or"}, `"or"`},
+		{[]string{"buy", "milk", "AND", "eggs"}, `buy milk "AND" eggs`},
+		{[]string{"not"}, `"not"`},
+		{[]string{"board:My board"}, `board:"My board"`},
+		{[]string{"is:open", "board:#release notes", "starred"}, `is:open board:"#release notes" starred`},
+		{[]string{"milk or eggs", "is:open"}, "(milk or eggs) is:open"},
+		{[]string{"board:#a or board:#b"}, "(board:#a or board:#b)"},
+		{[]string{"buy milk"}, "(buy milk)"},
+		{[]string{`"flaky test"`, "created:>2026-09-01"}, `"flaky test" created:>2026-09-01`},
+		{[]string{"-is:blocked", "  ", ""}, "-is:blocked"},
+		{[]string{`"open`}, `"open`},
+	}
+	for _, test := range tests {
+		if got := JoinQueryArgs(test.args); got != test.want {
+			t.Errorf("%q became %q, want %q", test.args, got, test.want)
+		}
+	}
+
+	// Each word given alone is searched for, rather than being read as an operator.
+	items, boards := queryItems()
+	q, err := ParseQuery(JoinQueryArgs([]string{"an", "idea", "or", "two"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Match(items["idea"], boards["idea"]) || q.Match(items["milk"], boards["milk"]) {
+		t.Errorf("an idea or two should match only the idea")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,16 +1,33 @@
 package data
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Repo holds the items and boards of a single book.
+//
+// A Repo is safe for concurrent use by multiple goroutines. Items returned by its methods are snapshots copied out
+// while the Repo is locked; changing a snapshot has no effect on the Repo, which must be mutated through its own
+// methods instead. Snapshots taken before a mutation are not updated by it.
 type Repo struct {
+	mu     sync.RWMutex
 	items  map[uint64]*Item
 	boards map[string]map[uint64]bool
+	views  map[string]string // queries saved in the book, by name
+	nextId uint64            // display number given to the next item created
+
+	// missing holds the Uids which boards refer to but which no item in the book has, by board. They are kept, rather
+	// than dropped on reading, so that Check can report them and the book is written back as it was read.
+	missing map[string][]string
+
+	recording bool     // whether changes are being recorded, see RecordChanges
+	changes   []Change // changes made since TakeChanges was last called
 }
 
 const DefaultBoard = "My board"
@@ -20,6 +37,8 @@ func NewRepo() *Repo {
 	result := new(Repo)
 	result.items = make(map[uint64]*Item)
 	result.boards = make(map[string]map[uint64]bool)
+	result.views = make(map[string]string)
+	result.missing = make(map[string][]string)
 
 	result.boards[DefaultBoard] = make(map[uint64]bool)
 	result.boards[ArchiveBoard] = make(map[uint64]bool)
@@ -27,12 +46,89 @@ func NewRepo() *Repo {
 	return result
 }
 
+// snapshot copies it so that it can be handed out of the Repo safely.
+func snapshot(it *Item) *Item {
+	if it == nil {
+		return nil
+	}
+	result := *it
+	return &result
+}
+
 func (store *Repo) Item(id uint64) *Item {
-	result, _ := store.items[id]
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return snapshot(store.items[id])
+}
+
+// Lookup returns the item referred to by ref, which may be either the item's display number or its Uid.
+func (store *Repo) Lookup(ref string) (*Item, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	if IsUid(ref) {
+		uid := strings.ToUpper(ref)
+		for _, it := range store.items {
+			if it != nil && it.Uid == uid {
+				return snapshot(it), nil
+			}
+		}
+		return nil, &NoSuchItemError{ref}
+	}
+	id, err := strconv.ParseUint(ref, 10, 64)
+	if err != nil || store.items[id] == nil {
+		return nil, &NoSuchItemError{ref}
+	}
+	return snapshot(store.items[id]), nil
+}
+
+// Len returns the number of items in the store, including archived items.
+func (store *Repo) Len() int {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return len(store.items)
+}
+
+// Items returns a snapshot of every item in the store, including archived items, ordered by display number.
+func (store *Repo) Items() []*Item {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	result := make([]*Item, 0, len(store.items))
+	for _, id := range store.sortedIds() {
+		if it := store.items[id]; it != nil {
+			result = append(result, snapshot(it))
+		}
+	}
+	return result
+}
+
+// BoardsOf returns the names of the boards an item belongs to, in alphabetical order.
+func (store *Repo) BoardsOf(id uint64) []string {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return store.boardsOf(id)
+}
+
+func (store *Repo) boardsOf(id uint64) []string {
+	var result []string
+	for name, board := range store.boards {
+		if board[id] {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
 	return result
 }
 
+// Boards returns the names of every board, in alphabetical order.
 func (store *Repo) Boards() []string {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
 	keys := make([]string, len(store.boards))
 
 	i := 0
@@ -40,10 +136,15 @@ func (store *Repo) Boards() []string {
 		keys[i] = k
 		i++
 	}
+	sort.Strings(keys)
 	return keys
 }
 
+// IdsInBoard returns the ids of the items on the named board in ascending order, or nil if there is no such board.
 func (store *Repo) IdsInBoard(name string) []uint64 {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
 	if store.boards[name] == nil {
 		return nil
 	}
@@ -54,26 +155,36 @@ func (store *Repo) IdsInBoard(name string) []uint64 {
 		result[i] = id
 		i += 1
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
 
 	return result
 }
 
+// ActiveItems returns snapshots of every item outside the archive, ordered by id.
 func (store *Repo) ActiveItems() []*Item {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
 	archive := store.boards[ArchiveBoard]
 
-	result := make([]*Item, 8)
-	for id, item := range store.items {
-		if !archive[id] {
-			result = append(result, item)
+	result := make([]*Item, 0, len(store.items))
+	for _, id := range store.sortedIds() {
+		if item := store.items[id]; item != nil && !archive[id] {
+			result = append(result, snapshot(item))
 		}
 	}
 	return result
 }
 
+// ItemsInBoards returns snapshots of the items on each of the given boards in turn, ordered by id within each board.
+// Items on several of the boards are returned once for each.
 func (store *Repo) ItemsInBoards(boards ...string) []*Item {
 	if len(boards) == 0 {
 		return nil
 	}
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
 	// Count total items in all requested boards
 	size := 0
 	for _, board := range boards {
@@ -86,74 +197,170 @@ func (store *Repo) ItemsInBoards(boards ...string) []*Item {
 	for _, board := range boards {
 		items, ok := store.boards[board]
 		if ok {
+			start := i
 			for itemid := range items {
-				result[i] = store.items[itemid]
-				i += 1
+				if it := store.items[itemid]; it != nil {
+					result[i] = snapshot(it)
+					i += 1
+				}
 			}
+			ids := result[start:i]
+			sort.Slice(ids, func(a, b int) bool { return ids[a].Id < ids[b].Id })
 		}
 	}
-	return result
+	return result[:i]
 }
 
-func (store *Repo) ToggleItemIsStarred(id uint64) {
+// ToggleItemIsStarred stars or unstars an item, returning a snapshot of the item afterwards, or nil if no item has the
+// given id.
+func (store *Repo) ToggleItemIsStarred(id uint64) *Item {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
 	it, ok := store.items[id]
-	if ok {
+	if ok && it != nil {
+		before := store.state(id)
 		it.SetStarred(!it.IsStarred())
+		store.record(OpStar, before, id)
 	}
+	return snapshot(it)
 }
 
-func (store *Repo) ToggleTaskIsComplete(id uint64) error {
+// ToggleTaskIsComplete checks or unchecks a task, returning a snapshot of the task afterwards, or nil if no item has
+// the given id. Closed tasks, whether done or cancelled, are unchecked back to Todo; any other task is checked off as
+// Done.
+func (store *Repo) ToggleTaskIsComplete(id uint64) (*Item, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
 	it, ok := store.items[id]
-	if ok {
+	if ok && it != nil {
 		if !it.IsTask() {
-			return &NotATaskError{id}
+			return snapshot(it), &NotATaskError{id}
+		}
+		before := store.state(id)
+		if it.State().IsClosed() {
+			store.setState(it, Todo)
+		} else {
+			store.setState(it, Done)
 		}
-		it.SetComplete(!it.IsComplete())
+		store.record(OpCheck, before, id)
 	}
-	return nil
+	return snapshot(it), nil
 }
 
 func (store *Repo) DeleteItem(id uint64) *Item {
-	it, _ := store.items[id]
-	store.items[id] = nil
-	return it
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	it := store.items[id]
+	before := store.state(id)
+	delete(store.items, id)
+	for _, board := range store.boards {
+		delete(board, id)
+	}
+	if before != nil {
+		store.record(OpDelete, before, id)
+	}
+	return snapshot(it)
+}
+
+// MoveItem replaces the boards an item belongs to, returning a snapshot of the item afterwards, or nil if no item has
+// the given id. Items moved to no boards at all are moved to DefaultBoard.
+func (store *Repo) MoveItem(id uint64, boards ...string) *Item {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	it := store.items[id]
+	if it == nil {
+		return nil
+	}
+	if len(boards) == 0 {
+		boards = []string{DefaultBoard}
+	}
+	before := store.state(id)
+	for _, board := range store.boards {
+		delete(board, id)
+	}
+	for _, board := range boards {
+		store.addItemToBoard(id, board)
+	}
+	store.record(OpMove, before, id)
+	return snapshot(it)
+}
+
+// EditItem replaces the description of an item, returning a snapshot of the item afterwards, or nil if no item has
+// the given id.
+func (store *Repo) EditItem(id uint64, desc string) *Item {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	it := store.items[id]
+	if it == nil {
+		return nil
+	}
+	before := store.state(id)
+	it.Desc = desc
+	store.record(OpEdit, before, id)
+	return snapshot(it)
 }
 
 func (store *Repo) MakeNote(desc string, boards ...string) *Item {
-	return store.makeItem(desc, boards...)
+	return store.makeItem(desc, 0, boards...)
 }
 
 func (store *Repo) MakeTask(desc string, boards ...string) *Item {
-	result := store.makeItem(desc, boards...)
-	result.flags = taskFlag
-
-	return result
+	return store.makeItem(desc, taskFlag, boards...)
 }
 
-var nextId uint64 = 0 // Using this here works only since collabbook only has one Repo open per execution
+func (store *Repo) makeItem(desc string, flags byte, boards ...string) *Item {
+	store.mu.Lock()
+	defer store.mu.Unlock()
 
-func (store *Repo) makeItem(desc string, boards ...string) *Item {
-	result := &Item{nextId, 0, time.Now(), desc}
-	nextId += 1
+	now := time.Now()
+	result := &Item{Id: store.nextId, Uid: newUid(now), flags: flags, CreatedUTC: now, Desc: desc}
+	store.nextId += 1
 
 	store.items[result.Id] = result
 
 	if len(boards) == 0 {
-		store.AddItemToBoard(result, DefaultBoard)
+		store.addItemToBoard(result.Id, DefaultBoard)
 	} else {
 		for _, board := range boards {
-			store.AddItemToBoard(result, board)
+			store.addItemToBoard(result.Id, board)
 		}
 	}
+	store.record(OpCreate, nil, result.Id)
+	return snapshot(result)
+}
+
+// sortedIds returns the id of every entry in the store in ascending order.
+func (store *Repo) sortedIds() []uint64 {
+	result := make([]uint64, 0, len(store.items))
+	for id := range store.items {
+		result = append(result, id)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
 	return result
 }
 
 func (store *Repo) AddItemToBoard(item *Item, boardname string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	before := store.state(item.Id)
+	store.addItemToBoard(item.Id, boardname)
+	if before != nil {
+		store.record(OpBoard, before, item.Id)
+	}
+}
+
+func (store *Repo) addItemToBoard(id uint64, boardname string) {
 	_, ok := store.boards[boardname]
 	if !ok {
 		store.boards[boardname] = make(map[uint64]bool)
 	}
-	store.boards[boardname][item.Id] = true
+	store.boards[boardname][id] = true
 }
 
 type NotATaskError struct {
@@ -164,102 +371,175 @@ func (err *NotATaskError) Error() string {
 	return fmt.Sprintf("%d is not marked as a task", err.id)
 }
 
+type NoSuchItemError struct {
+	ref string
+}
+
+func (err *NoSuchItemError) Error() string {
+	return fmt.Sprintf("%s does not refer to any item", err.ref)
+}
+
 //----------------------------------------------------------------------------------------------------------------------
 // TextMarshaler related code below
 //----------------------------------------------------------------------------------------------------------------------
 
-type CouldNotParse struct{}
-
-func (err *CouldNotParse) Error() string {
-	return fmt.Sprintf("Could not unmarshal Repo.")
-}
-
-type UnexpectedEndOfInput struct{}
+// UnmarshalText reads a book into the store. Problems are reported as ParseErrors, carrying the line and field on which
+// each was found; items which could be read are added to the store even when errors are returned.
+func (store *Repo) UnmarshalText(text []byte) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	p := newParser(text)
+	items := p.items()
+	boards := p.boards()
+	store.settle(items, boards)
+	for name, query := range p.views() {
+		store.views[name] = query
+	}
 
-func (err *UnexpectedEndOfInput) Error() string {
-	return fmt.Sprintf("Unexpected end of input")
+	if len(p.errs) > 0 {
+		return p.errs
+	}
+	return nil
 }
 
-func (store *Repo) UnmarshalText(text []byte) (err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = &CouldNotParse{}
+// settle adds freshly parsed items and board memberships to the store. Items written before Uids existed are given one,
+// and items whose display number collides with another item's are renumbered. Renumbering depends only on the Ids and
+// Uids involved, so every collaborator loading the same merged book sees the same numbers.
+func (store *Repo) settle(items []*Item, boards map[string][]string) {
+	byUid := make(map[string][]*Item, len(items))
+	byId := make(map[uint64]*Item, len(items))
+	for _, it := range items {
+		if it.Uid == "" {
+			it.Uid = newUid(it.CreatedUTC)
 		}
-	}()
-
-	s := bufio.NewScanner(bytes.NewReader(text))
+		byUid[it.Uid] = append(byUid[it.Uid], it)
+		if _, ok := byId[it.Id]; !ok {
+			byId[it.Id] = it
+		}
+	}
 
-	done := false
-	for !done {
-		done, err = unmarshalItem(s, store)
+	sorted := make([]*Item, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Id != sorted[j].Id {
+			return sorted[i].Id < sorted[j].Id
+		}
+		return sorted[i].Uid < sorted[j].Uid
+	})
+
+	var clashed []*Item
+	for _, it := range sorted {
+		if _, taken := store.items[it.Id]; taken {
+			clashed = append(clashed, it)
+			continue
+		}
+		store.items[it.Id] = it
+		store.nextId = max(it.Id+1, store.nextId)
 	}
-	done = false
-	for !done {
-		done, err = unmarshalBoard(s, store)
+	for _, it := range clashed {
+		it.Id = store.nextId
+		store.items[it.Id] = it
+		store.nextId += 1
+	}
+
+	// Board entries name items by Uid, or by display number in books written before Uids existed.
+	for name, refs := range boards {
+		board, ok := store.boards[name]
+		if !ok {
+			board = make(map[uint64]bool, len(refs))
+			store.boards[name] = board
+		}
+		for _, ref := range refs {
+			if IsUid(ref) {
+				found := byUid[strings.ToUpper(ref)]
+				for _, it := range found {
+					board[it.Id] = true
+				}
+				if len(found) == 0 && !contains(store.missing[name], strings.ToUpper(ref)) {
+					store.missing[name] = append(store.missing[name], strings.ToUpper(ref))
+				}
+			} else if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+				if it, ok := byId[id]; ok {
+					board[it.Id] = true
+				} else {
+					// The reference is kept for Check to report, so no new item may take its number.
+					board[id] = true
+					store.nextId = max(id+1, store.nextId)
+				}
+			}
+		}
 	}
-	return
 }
 
+// MarshalText writes the store in a stable order, so that unchanged books marshal to identical text.
 func (store *Repo) MarshalText() (text []byte, err error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
 	buf := new(bytes.Buffer)
 
-	for _, item := range store.items {
-		err = marshalItem(item, buf)
+	for _, id := range store.sortedIds() {
+		if item := store.items[id]; item != nil {
+			err = marshalItem(item, buf)
+		}
 	}
 	buf.WriteString("=====\n")
 
-	for name, board := range store.boards {
-		err = marshalBoard(name, board, buf)
+	names := make([]string, 0, len(store.boards))
+	for name := range store.boards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		err = marshalBoard(name, store.boards[name], store.missing[name], store.items, buf)
+	}
+
+	// Books without views end after their boards, as they did before views were introduced.
+	if len(store.views) > 0 {
+		buf.WriteString("=====\n")
+		for _, name := range store.viewNames() {
+			buf.WriteString(escapeLine(name) + "\n" + escapeLine(store.views[name]) + "\n---\n")
+		}
 	}
 
 	return buf.Bytes(), err
 }
 
-func marshalBoard(name string, board map[uint64]bool, buf *bytes.Buffer) (err error) {
-	_, err = buf.WriteString(name)
+func marshalBoard(name string, board map[uint64]bool, missing []string, items map[uint64]*Item, buf *bytes.Buffer) (err error) {
+	_, err = buf.WriteString(escapeLine(name))
 	_, err = buf.WriteRune('\n')
 
+	ids := make([]uint64, 0, len(board))
 	for itemid := range board {
-		_, err = buf.WriteString(strconv.FormatUint(itemid, 10))
-		_, err = buf.WriteRune('\n')
-	}
-	_, err = buf.WriteString("---\n")
-	return
-}
-
-func unmarshalBoard(s *bufio.Scanner, store *Repo) (done bool, err error) {
-	if !s.Scan() {
-		return true, nil
+		ids = append(ids, itemid)
 	}
-	tok := s.Text()
-	board := make(map[uint64]bool, 4)
-	store.boards[tok] = board
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
 
-	for s.Scan() {
-		tok = s.Text()
-		if tok == "---" {
-			return false, err
+	for _, itemid := range ids {
+		if it := items[itemid]; it != nil {
+			_, err = buf.WriteString(it.Uid)
+		} else {
+			_, err = buf.WriteString(strconv.FormatUint(itemid, 10))
 		}
-
-		var itemid uint64
-		itemid, err = strconv.ParseUint(tok, 10, 64)
-
-		board[itemid] = true
+		_, err = buf.WriteRune('\n')
 	}
-	return true, err
+	for _, uid := range missing {
+		_, err = buf.WriteString(uid + "\n")
+	}
+	_, err = buf.WriteString("---\n")
+	return
 }
 
 func marshalItem(it *Item, buf *bytes.Buffer) (err error) {
 	_, err = buf.WriteString(strconv.FormatUint(it.Id, 10))
+	_, err = buf.WriteRune(' ')
+	_, err = buf.WriteString(it.Uid)
 	_, err = buf.WriteRune('\n')
 
 	if it.IsTask() {
 		_, err = buf.WriteString("T\n")
-		if it.IsComplete() {
-			_, err = buf.WriteString("T\n")
-		} else {
-			_, err = buf.WriteString("F\n")
-		}
+		_, err = buf.WriteString(stateLetters[it.State()] + "\n")
 	} else {
 		_, err = buf.WriteString("N\n")
 	}
@@ -270,12 +550,20 @@ func marshalItem(it *Item, buf *bytes.Buffer) (err error) {
 		_, err = buf.WriteString("F\n")
 	}
 
+	// The date line holds the creation time, followed by the completion and reopening times of tasks which have them.
 	date, err := it.CreatedUTC.MarshalText()
-
 	_, err = buf.Write(date)
+	if !it.CompletedUTC.IsZero() || !it.ReopenedUTC.IsZero() {
+		_, err = buf.WriteRune(' ')
+		_, err = buf.WriteString(marshalOptionalTime(it.CompletedUTC))
+	}
+	if !it.ReopenedUTC.IsZero() {
+		_, err = buf.WriteRune(' ')
+		_, err = buf.WriteString(marshalOptionalTime(it.ReopenedUTC))
+	}
 	_, err = buf.WriteRune('\n')
 
-	_, err = buf.WriteString(it.Desc)
+	_, err = buf.WriteString(escapeLine(it.Desc))
 	_, err = buf.WriteRune('\n')
 
 	// Write Item separator
@@ -283,6 +571,14 @@ func marshalItem(it *Item, buf *bytes.Buffer) (err error) {
 	return
 }
 
+// marshalOptionalTime writes t in RFC 3339 format, or as "-" if t is zero.
+func marshalOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
 func max(a, b uint64) uint64 {
 	if (a > b) {
 		return a
@@ -290,46 +586,11 @@ func max(a, b uint64) uint64 {
 	return b
 }
 
-func unmarshalItem(s *bufio.Scanner, store *Repo) (done bool, err error) {
-	it := new(Item)
-	s.Scan()
-	tok := s.Text()
-	if tok == "=====" {
-		return true, nil
-	}
-
-	it.Id, err = strconv.ParseUint(tok, 10, 64)
-	store.items[it.Id] = it
-	nextId = max(it.Id + 1, nextId)
-
-	s.Scan()
-	tok = s.Text()
-	if tok[0] == 'T' {
-		it.flags = taskFlag
-		s.Scan()
-		tok = s.Text()
-		if tok[0] == 'T' {
-			it.SetComplete(true)
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
 		}
 	}
-	s.Scan()
-	tok = s.Text()
-	if tok[0] == 'T' {
-		it.SetStarred(true)
-	}
-
-	s.Scan()
-	tok = s.Text()
-	it.CreatedUTC, err = time.Parse(time.RFC3339, tok)
-
-	s.Scan()
-	it.Desc = s.Text()
-
-	// Check separator to see if there are more items to read
-	if s.Scan() == false {
-		return true, &UnexpectedEndOfInput{}
-	}
-
-	err = s.Err()
-	return
+	return false
 }
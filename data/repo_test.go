@@ -0,0 +1,132 @@
+package data
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestRepoConcurrentUse hammers a Repo from several goroutines at once. It is meant to be run with -race, which
+// reports any access to the Repo's state made without holding its lock.
+func TestRepoConcurrentUse(t *testing.T) {
+	store := NewRepo()
+	const workers, rounds = 8, 100
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			board := fmt.Sprintf("#board-%d", w%3)
+			for i := 0; i < rounds; i++ {
+				it := store.MakeTask(fmt.Sprintf("task %d-%d", w, i), board)
+				if _, err := store.ToggleTaskIsComplete(it.Id); err != nil {
+					t.Errorf("toggling task %d: %v", it.Id, err)
+					return
+				}
+				store.ToggleItemIsStarred(it.Id)
+				store.MoveItem(it.Id, "#moved", board)
+				store.EditItem(it.Id, "edited")
+				if _, err := store.MarshalText(); err != nil {
+					t.Errorf("marshalling: %v", err)
+					return
+				}
+				store.Items()
+				store.Boards()
+				store.IdsInBoard("#moved")
+				store.ItemsInBoards(board, "#moved")
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if n := store.Len(); n != workers*rounds {
+		t.Fatalf("got %d items, want %d", n, workers*rounds)
+	}
+	seen := make(map[uint64]bool)
+	for _, it := range store.Items() {
+		if seen[it.Id] {
+			t.Fatalf("display number %d given out twice", it.Id)
+		}
+		seen[it.Id] = true
+		if !it.IsComplete() || !it.IsStarred() || it.Desc != "edited" {
+			t.Errorf("item %d lost a change: %+v", it.Id, it)
+		}
+	}
+	if n := len(store.IdsInBoard("#moved")); n != workers*rounds {
+		t.Errorf("got %d items on #moved, want %d", n, workers*rounds)
+	}
+}
+
+// TestRepoSnapshots checks that items handed out by a Repo are copies, unaffected by later changes and unable to
+// change the Repo themselves.
+func TestRepoSnapshots(t *testing.T) {
+	store := NewRepo()
+	it := store.MakeTask("original")
+
+	it.Desc = "changed outside"
+	if got := store.Item(it.Id).Desc; got != "original" {
+		t.Errorf("changing a snapshot changed the Repo: got %q", got)
+	}
+
+	before := store.Item(it.Id)
+	store.EditItem(it.Id, "edited")
+	if before.Desc != "original" {
+		t.Errorf("editing the Repo changed an earlier snapshot: got %q", before.Desc)
+	}
+
+	deleted := store.DeleteItem(it.Id)
+	if deleted == nil || deleted.Desc != "edited" || store.Item(it.Id) != nil {
+		t.Fatalf("DeleteItem should remove the item and return how it was: got %+v", deleted)
+	}
+	deleted.Desc = "changed after delete"
+	if before.Desc != "original" {
+		t.Errorf("changing a deleted item's snapshot changed an earlier snapshot: got %q", before.Desc)
+	}
+}
+
+// TestRepoConcurrentReadWhileMarshalling checks that books marshalled while items are being added always read back.
+func TestRepoConcurrentReadWhileMarshalling(t *testing.T) {
+	store := NewRepo()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 500; i++ {
+			store.MakeNote(fmt.Sprintf("note %d", i), "#notes")
+		}
+	}()
+
+	for {
+		text, err := store.MarshalText()
+		if err != nil {
+			t.Fatalf("marshalling: %v", err)
+		}
+		if err := NewRepo().UnmarshalText(text); err != nil {
+			t.Fatalf("reading back a book marshalled mid-write: %v", err)
+		}
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
+
+func TestRecordChanges(t *testing.T) {
+	store := NewRepo()
+	store.MakeTask("before recording")
+	if changes := store.TakeChanges(); len(changes) != 0 {
+		t.Errorf("got %d changes before recording began, want none", len(changes))
+	}
+
+	store.RecordChanges()
+	it := store.MakeTask("after recording")
+	store.ToggleTaskIsComplete(it.Id)
+	changes := store.TakeChanges()
+	if len(changes) != 2 || changes[0].Op != OpCreate || changes[1].Op != OpCheck {
+		t.Errorf("got changes %v, want a create and a check", changes)
+	}
+	if changes := store.TakeChanges(); len(changes) != 0 {
+		t.Errorf("got %d changes after taking them, want none", len(changes))
+	}
+}
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// State is where a task stands between being created and being finished with.
+type State int
+
+const (
+	Todo       State = iota // not yet started
+	InProgress              // being worked on
+	Blocked                 // waiting on something else
+	Done                    // finished; the only state in which a task is complete
+	Cancelled               // no longer needed
+)
+
+// States lists every State in order.
+var States = []State{Todo, InProgress, Blocked, Done, Cancelled}
+
+var stateNames = map[State]string{
+	Todo:       "todo",
+	InProgress: "in-progress",
+	Blocked:    "blocked",
+	Done:       "done",
+	Cancelled:  "cancelled",
+}
+
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *State) UnmarshalText(text []byte) (err error) {
+	*s, err = ParseState(string(text))
+	return err
+}
+
+// ParseState returns the State with the given name.
+func ParseState(name string) (State, error) {
+	for _, s := range States {
+		if strings.EqualFold(name, s.String()) {
+			return s, nil
+		}
+	}
+	return Todo, fmt.Errorf("unknown state %q; expected todo, in-progress, blocked, done or cancelled", name)
+}
+
+// IsClosed reports whether a task in this state needs no more work.
+func (s State) IsClosed() bool {
+	return s == Done || s == Cancelled
+}
+
+// transitions lists the states a task may move to from each state. Closed tasks must be reopened, or begun again,
+// before they can be blocked, finished or cancelled.
+var transitions = map[State][]State{
+	Todo:       {InProgress, Blocked, Done, Cancelled},
+	InProgress: {Todo, Blocked, Done, Cancelled},
+	Blocked:    {Todo, InProgress, Done, Cancelled},
+	Done:       {Todo, InProgress},
+	Cancelled:  {Todo, InProgress},
+}
+
+// CanTransition reports whether a task may move from one state to another.
+func CanTransition(from, to State) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+type InvalidTransitionError struct {
+	Id       uint64
+	From, To State
+}
+
+func (err *InvalidTransitionError) Error() string {
+	if err.From == err.To {
+		return fmt.Sprintf("Task %d is already %s", err.Id, err.From)
+	}
+	return fmt.Sprintf("Task %d cannot go from %s to %s", err.Id, err.From, err.To)
+}
+
+// stateLetters are written in place of the complete flag of tasks in a book. Books written before tasks had states
+// only ever hold T and F.
+var stateLetters = map[State]string{
+	Todo:       "F",
+	InProgress: "P",
+	Blocked:    "B",
+	Done:       "T",
+	Cancelled:  "C",
+}
+
+func stateOfLetter(letter string) (State, bool) {
+	for s, l := range stateLetters {
+		if l == letter {
+			return s, true
+		}
+	}
+	return Todo, false
+}
+
+// SetTaskState moves a task to a new state, returning a snapshot of the task afterwards, or nil if no item has the
+// given id. Finishing a task records when it was completed, and moving a completed task to any other state records
+// when it was reopened.
+func (store *Repo) SetTaskState(id uint64, state State) (*Item, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	it := store.items[id]
+	if it == nil {
+		return nil, nil
+	}
+	if !it.IsTask() {
+		return snapshot(it), &NotATaskError{id}
+	}
+	if !CanTransition(it.State(), state) {
+		return snapshot(it), &InvalidTransitionError{id, it.State(), state}
+	}
+	before := store.state(id)
+	store.setState(it, state)
+	store.record(OpState, before, id)
+	return snapshot(it), nil
+}
+
+func (store *Repo) setState(it *Item, state State) {
+	wasComplete := it.IsComplete()
+	it.SetState(state)
+	switch {
+	case it.IsComplete():
+		it.CompletedUTC = time.Now().UTC()
+	case wasComplete:
+		it.CompletedUTC = time.Time{}
+		it.ReopenedUTC = time.Now().UTC()
+	}
+}
@@ -0,0 +1,212 @@
+package data
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// StatsOptions chooses the periods covered by Stats.
+type StatsOptions struct {
+	Days         int           // number of days to count completions for
+	Weeks        int           // number of seven day periods to count creations, completions and throughput for
+	OverdueAfter time.Duration // how long a task may stay open before it counts as overdue
+	Oldest       int           // number of the oldest open tasks to list
+}
+
+// Stats summarises how the tasks in a Repo have been created and completed over the days and weeks leading up to Now.
+// Archived items are counted towards completions, but not towards starred, overdue or oldest open items.
+type Stats struct {
+	Now            time.Time
+	PerDay         []int           // tasks completed on each day, oldest first and ending with the day of Now
+	PerWeek        []int           // tasks completed in each seven day period, oldest first and ending with the day of Now
+	CreatedPerWeek []int           // tasks created in each of the periods covered by PerWeek
+	LeadTime       time.Duration   // median time from creation to completion over every complete task
+	Measured       int             // number of complete tasks LeadTime was measured over
+	Boards         []BoardActivity // activity on each board, ordered by name
+	Starred        int             // number of starred items
+	Overdue        int             // number of tasks open for longer than StatsOptions.OverdueAfter
+	Oldest         []*Item         // the tasks which have been open longest, oldest first
+}
+
+// BoardActivity counts the tasks on a single board.
+type BoardActivity struct {
+	Name      string
+	Done      int // tasks which are complete
+	Completed int // tasks completed within the weeks covered by Stats.PerWeek
+	Open      int // tasks which are still open
+}
+
+// Stats summarises the tasks in the store over the periods chosen by opts, up to now. Days begin at midnight in the
+// location of now. Tasks completed before completion times were recorded count only towards their board's Done.
+// Negative options are treated as zero.
+func (store *Repo) Stats(now time.Time, opts StatsOptions) Stats {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	if opts.Days < 0 {
+		opts.Days = 0
+	}
+	if opts.Weeks < 0 {
+		opts.Weeks = 0
+	}
+	if opts.OverdueAfter < 0 {
+		opts.OverdueAfter = 0
+	}
+
+	result := Stats{
+		Now:            now,
+		PerDay:         make([]int, opts.Days),
+		PerWeek:        make([]int, opts.Weeks),
+		CreatedPerWeek: make([]int, opts.Weeks),
+	}
+	tomorrow := startOfDay(now).AddDate(0, 0, 1)
+	since := tomorrow.AddDate(0, 0, -7*opts.Weeks)
+	archive := store.boards[ArchiveBoard]
+
+	var leadTimes []time.Duration
+	var open []*Item
+	for id, it := range store.items {
+		if it == nil {
+			continue
+		}
+		if it.IsStarred() && !archive[id] {
+			result.Starred += 1
+		}
+		if !it.IsTask() {
+			continue
+		}
+		if created := it.CreatedUTC.In(now.Location()); created.Before(tomorrow) {
+			if week := daysBetween(created, tomorrow) / 7; week < opts.Weeks {
+				result.CreatedPerWeek[opts.Weeks-1-week] += 1
+			}
+		}
+		if it.State() == Cancelled {
+			continue
+		}
+		if !it.IsComplete() {
+			if !archive[id] {
+				open = append(open, it)
+				if now.Sub(it.CreatedUTC) > opts.OverdueAfter {
+					result.Overdue += 1
+				}
+			}
+			continue
+		}
+		if it.CompletedUTC.IsZero() {
+			continue
+		}
+		leadTimes = append(leadTimes, it.CompletedUTC.Sub(it.CreatedUTC))
+
+		completed := it.CompletedUTC.In(now.Location())
+		if !completed.Before(tomorrow) {
+			continue
+		}
+		if day := daysBetween(completed, tomorrow); day < opts.Days {
+			result.PerDay[opts.Days-1-day] += 1
+		}
+		if week := daysBetween(completed, tomorrow) / 7; week < opts.Weeks {
+			result.PerWeek[opts.Weeks-1-week] += 1
+		}
+	}
+
+	if len(leadTimes) > 0 {
+		sort.Slice(leadTimes, func(i, j int) bool { return leadTimes[i] < leadTimes[j] })
+		mid := len(leadTimes) / 2
+		result.LeadTime = leadTimes[mid]
+		if len(leadTimes)%2 == 0 {
+			result.LeadTime = (leadTimes[mid-1] + leadTimes[mid]) / 2
+		}
+		result.Measured = len(leadTimes)
+	}
+
+	sort.Slice(open, func(i, j int) bool {
+		if !open[i].CreatedUTC.Equal(open[j].CreatedUTC) {
+			return open[i].CreatedUTC.Before(open[j].CreatedUTC)
+		}
+		return open[i].Id < open[j].Id
+	})
+	for i := 0; i < len(open) && i < opts.Oldest; i++ {
+		result.Oldest = append(result.Oldest, snapshot(open[i]))
+	}
+
+	for name, board := range store.boards {
+		activity := BoardActivity{Name: name}
+		for id := range board {
+			it := store.items[id]
+			if it == nil || !it.IsTask() || it.State() == Cancelled {
+				continue
+			}
+			if !it.IsComplete() {
+				activity.Open += 1
+				continue
+			}
+			activity.Done += 1
+			if !it.CompletedUTC.Before(since) && it.CompletedUTC.Before(tomorrow) {
+				activity.Completed += 1
+			}
+		}
+		result.Boards = append(result.Boards, activity)
+	}
+	sort.Slice(result.Boards, func(i, j int) bool { return result.Boards[i].Name < result.Boards[j].Name })
+
+	return result
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween counts the whole days from the end of the day of t until end, which must fall on a midnight. Rounding
+// absorbs days lengthened or shortened by daylight saving.
+func daysBetween(t, end time.Time) int {
+	return int(math.Round(end.Sub(startOfDay(t)).Hours()/24)) - 1
+}
+
+// Burndown counts the tasks on a board which were still open at the end of each day from the day of from to the day
+// of to, inclusive. Days begin at midnight in the location of from. Tasks completed before completion times were
+// recorded are left out, and reopened tasks count as open from their creation until their latest completion.
+func (store *Repo) Burndown(board string, from, to time.Time) []int {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	first := startOfDay(from)
+	days := daysBetween(first, startOfDay(to.In(from.Location())).AddDate(0, 0, 1)) + 1
+	if days <= 0 {
+		return nil
+	}
+	result := make([]int, days)
+
+	for id := range store.boards[board] {
+		it := store.items[id]
+		if it == nil || !it.IsTask() || it.State() == Cancelled || (it.IsComplete() && it.CompletedUTC.IsZero()) {
+			continue
+		}
+		for day := 0; day < days; day++ {
+			end := first.AddDate(0, 0, day+1)
+			if !it.CreatedUTC.Before(end) {
+				continue
+			}
+			if it.IsComplete() && it.CompletedUTC.Before(end) {
+				continue
+			}
+			result[day] += 1
+		}
+	}
+	return result
+}
+
+// Earliest returns the creation time of the oldest task on a board, or the zero time if the board has no tasks.
+func (store *Repo) Earliest(board string) time.Time {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	var result time.Time
+	for id := range store.boards[board] {
+		it := store.items[id]
+		if it != nil && it.IsTask() && (result.IsZero() || it.CreatedUTC.Before(result)) {
+			result = it.CreatedUTC
+		}
+	}
+	return result
+}
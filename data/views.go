@@ -0,0 +1,48 @@
+package data
+
+import "sort"
+
+// Views returns the names of the views saved in the book, in alphabetical order.
+func (store *Repo) Views() []string {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return store.viewNames()
+}
+
+func (store *Repo) viewNames() []string {
+	names := make([]string, 0, len(store.views))
+	for name := range store.views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// View returns the query of the named view, and whether the book has a view with that name.
+func (store *Repo) View(name string) (string, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	query, ok := store.views[name]
+	return query, ok
+}
+
+// SaveView saves query in the book under name, replacing any view with the same name. Views are not items, so saving
+// one records no change.
+func (store *Repo) SaveView(name, query string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.views[name] = query
+}
+
+// DeleteView removes the named view from the book, reporting whether there was one.
+func (store *Repo) DeleteView(name string) bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	_, ok := store.views[name]
+	delete(store.views, name)
+	return ok
+}
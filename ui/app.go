@@ -0,0 +1,355 @@
+// Package ui provides a full-screen terminal interface to a book, for triaging many items without running a command
+// for each one.
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell"
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+)
+
+// App shows each board of a Repo as a pane of items, and changes the items in response to keys.
+type App struct {
+	screen tcell.Screen
+	store  *data.Repo
+	theme  view.Theme
+	save   func() error
+
+	panes  []pane
+	focus  int      // index of the focused pane
+	left   int      // index of the leftmost pane on screen
+	filter []string // when not empty, only items matching every term are shown
+	prompt *prompt  // when not nil, keys are typed into the prompt
+	status string
+	quit   bool
+}
+
+// pane lists the items on a single board.
+type pane struct {
+	board  string
+	items  []*data.Item
+	cursor int // index of the selected item
+	top    int // index of the first item on screen
+}
+
+// prompt reads a line of text at the bottom of the screen.
+type prompt struct {
+	label  string
+	text   []rune
+	cursor int
+	done   func(text string)
+}
+
+// Run shows store on the terminal until the user quits. Changes are made to store as they are asked for, and save is
+// called after each one so that no work is lost if the terminal goes away.
+func Run(store *data.Repo, theme view.Theme, save func() error) error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+
+	app := &App{screen: screen, store: store, theme: theme, save: save}
+	app.status = "? space check  s star  e edit  m move  / search  q quit"
+	app.refresh()
+	for !app.quit {
+		app.draw()
+		switch ev := screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			screen.Sync()
+		case *tcell.EventKey:
+			if app.prompt != nil {
+				app.handlePromptKey(ev)
+			} else {
+				app.handleKey(ev)
+			}
+		}
+	}
+	return nil
+}
+
+// refresh reloads the panes from the store, keeping the same board focused and the same item selected where possible.
+func (app *App) refresh() {
+	focusBoard := ""
+	if app.focus < len(app.panes) {
+		focusBoard = app.panes[app.focus].board
+	}
+	selected := make(map[string]uint64, len(app.panes))
+	for _, p := range app.panes {
+		if it := p.selected(); it != nil {
+			selected[p.board] = it.Id
+		}
+	}
+	old := make(map[string]pane, len(app.panes))
+	for _, p := range app.panes {
+		old[p.board] = p
+	}
+
+	boards := app.store.Boards()
+	view.SortBoards(boards)
+
+	app.panes = app.panes[:0]
+	for _, board := range boards {
+		if board == data.ArchiveBoard {
+			continue
+		}
+		p := pane{board: board, top: old[board].top}
+		for _, id := range app.store.IdsInBoard(board) {
+			if it := app.store.Item(id); it != nil && it.Matches(app.filter...) {
+				p.items = append(p.items, it)
+			}
+		}
+
+		p.cursor = old[board].cursor
+		for i, it := range p.items {
+			if id, ok := selected[board]; ok && it.Id == id {
+				p.cursor = i
+			}
+		}
+		p.clamp()
+		if board == focusBoard {
+			app.focus = len(app.panes)
+		}
+		app.panes = append(app.panes, p)
+	}
+	if app.focus >= len(app.panes) {
+		app.focus = len(app.panes) - 1
+	}
+	if app.focus < 0 {
+		app.focus = 0
+	}
+}
+
+func (p *pane) selected() *data.Item {
+	if p.cursor < 0 || p.cursor >= len(p.items) {
+		return nil
+	}
+	return p.items[p.cursor]
+}
+
+// clamp keeps the cursor on an item.
+func (p *pane) clamp() {
+	if p.cursor >= len(p.items) {
+		p.cursor = len(p.items) - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+}
+
+// selected returns the item under the cursor of the focused pane, if any.
+func (app *App) selected() *data.Item {
+	if len(app.panes) == 0 {
+		return nil
+	}
+	return app.panes[app.focus].selected()
+}
+
+func (app *App) handleKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyCtrlC:
+		app.quit = true
+	case tcell.KeyEscape:
+		if len(app.filter) > 0 {
+			app.search("")
+		} else {
+			app.quit = true
+		}
+	case tcell.KeyLeft, tcell.KeyBacktab:
+		app.moveFocus(-1)
+	case tcell.KeyRight, tcell.KeyTab:
+		app.moveFocus(1)
+	case tcell.KeyUp:
+		app.moveCursor(-1)
+	case tcell.KeyDown:
+		app.moveCursor(1)
+	case tcell.KeyPgUp:
+		app.moveCursor(-app.pageSize())
+	case tcell.KeyPgDn:
+		app.moveCursor(app.pageSize())
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			app.quit = true
+		case 'h':
+			app.moveFocus(-1)
+		case 'l':
+			app.moveFocus(1)
+		case 'k':
+			app.moveCursor(-1)
+		case 'j':
+			app.moveCursor(1)
+		case ' ':
+			app.check()
+		case 's':
+			app.star()
+		case 'e':
+			app.edit()
+		case 'm':
+			app.move()
+		case '/':
+			app.prompt = newPrompt("Search: ", strings.Join(app.filter, " "), app.search)
+		case '?':
+			app.status = "arrows or hjkl move  space check  s star  e edit  m move  / search  esc clear search  q quit"
+		}
+	}
+}
+
+func (app *App) moveFocus(delta int) {
+	if len(app.panes) == 0 {
+		return
+	}
+	app.focus = (app.focus + delta + len(app.panes)) % len(app.panes)
+}
+
+func (app *App) moveCursor(delta int) {
+	if len(app.panes) == 0 {
+		return
+	}
+	p := &app.panes[app.focus]
+	p.cursor += delta
+	p.clamp()
+}
+
+func (app *App) check() {
+	it := app.selected()
+	if it == nil {
+		return
+	}
+	it, err := app.store.ToggleTaskIsComplete(it.Id)
+	if err != nil {
+		app.status = err.Error()
+		return
+	}
+	if it.IsComplete() {
+		app.commit("Checked task " + strconv.FormatUint(it.Id, 10))
+	} else {
+		app.commit("Unchecked task " + strconv.FormatUint(it.Id, 10))
+	}
+}
+
+func (app *App) star() {
+	it := app.selected()
+	if it == nil {
+		return
+	}
+	if it = app.store.ToggleItemIsStarred(it.Id); it.IsStarred() {
+		app.commit("Starred item " + strconv.FormatUint(it.Id, 10))
+	} else {
+		app.commit("Unstarred item " + strconv.FormatUint(it.Id, 10))
+	}
+}
+
+func (app *App) edit() {
+	it := app.selected()
+	if it == nil {
+		return
+	}
+	id := it.Id
+	app.prompt = newPrompt("Edit: ", it.Desc, func(text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			app.status = "Descriptions cannot be empty"
+			return
+		}
+		app.store.EditItem(id, text)
+		app.commit("Edited item " + strconv.FormatUint(id, 10))
+	})
+}
+
+func (app *App) move() {
+	it := app.selected()
+	if it == nil {
+		return
+	}
+	id := it.Id
+	var current []string
+	for _, board := range app.store.BoardsOf(id) {
+		if board != data.DefaultBoard {
+			current = append(current, board)
+		}
+	}
+	app.prompt = newPrompt("Move to #boards (none for "+data.DefaultBoard+"): ", strings.Join(current, " "),
+		func(text string) {
+			boards := strings.Fields(text)
+			for _, board := range boards {
+				if board[0] != '#' && board[0] != '@' {
+					app.status = "Board names start with # or @, not " + board
+					return
+				}
+			}
+			app.store.MoveItem(id, boards...)
+			app.commit("Moved item " + strconv.FormatUint(id, 10))
+		})
+}
+
+// search shows only the items matching every word of text, or every item when text is empty.
+func (app *App) search(text string) {
+	app.filter = strings.Fields(text)
+	app.refresh()
+	if len(app.filter) > 0 {
+		app.status = "Showing items matching " + strconv.Quote(strings.Join(app.filter, " ")) + "; esc to show all"
+	} else {
+		app.status = ""
+	}
+}
+
+// commit saves the changes made so far and reloads the panes.
+func (app *App) commit(message string) {
+	if err := app.save(); err != nil {
+		app.status = strings.Replace(err.Error(), "\n\t", " ", -1)
+	} else {
+		app.status = message
+	}
+	app.refresh()
+}
+
+func newPrompt(label, text string, done func(string)) *prompt {
+	runes := []rune(text)
+	return &prompt{label: label, text: runes, cursor: len(runes), done: done}
+}
+
+func (app *App) handlePromptKey(ev *tcell.EventKey) {
+	p := app.prompt
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		app.prompt = nil
+		p.done(string(p.text))
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		app.prompt = nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if p.cursor > 0 {
+			p.text = append(p.text[:p.cursor-1], p.text[p.cursor:]...)
+			p.cursor--
+		}
+	case tcell.KeyDelete:
+		if p.cursor < len(p.text) {
+			p.text = append(p.text[:p.cursor], p.text[p.cursor+1:]...)
+		}
+	case tcell.KeyLeft:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case tcell.KeyRight:
+		if p.cursor < len(p.text) {
+			p.cursor++
+		}
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		p.cursor = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		p.cursor = len(p.text)
+	case tcell.KeyCtrlU:
+		p.text, p.cursor = p.text[:0], 0
+	case tcell.KeyRune:
+		p.text = append(p.text, 0)
+		copy(p.text[p.cursor+1:], p.text[p.cursor:])
+		p.text[p.cursor] = ev.Rune()
+		p.cursor++
+	}
+}
@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gdamore/tcell"
+	"github.com/kalexmills/collabbook-go/data"
+	"github.com/kalexmills/collabbook-go/view"
+	"github.com/mattn/go-runewidth"
+)
+
+// minPaneWidth is the narrowest a pane may be before boards scroll off the side of the screen.
+const minPaneWidth = 30
+
+// draw redraws the whole screen: a title line, the panes, a status line and a line for prompts.
+func (app *App) draw() {
+	s := app.screen
+	s.Clear()
+	w, h := s.Size()
+
+	title := " collabbook"
+	if len(app.filter) > 0 {
+		title += "  search: " + strings.Join(app.filter, " ")
+	}
+	app.fill(0, 0, w, tcell.StyleDefault.Reverse(true))
+	app.drawText(0, 0, w, title, tcell.StyleDefault.Reverse(true))
+
+	if len(app.panes) == 0 {
+		app.drawText(1, 2, w-1, "No boards", tcell.StyleDefault.Dim(true))
+	} else {
+		paneWidth, visible := app.layout(w)
+		for i := app.left; i < app.left+visible && i < len(app.panes); i++ {
+			x := (i - app.left) * paneWidth
+			app.drawPane(&app.panes[i], x, 1, paneWidth-1, h-3, i == app.focus)
+			if i < app.left+visible-1 {
+				for y := 1; y < h-2; y++ {
+					s.SetContent(x+paneWidth-1, y, '│', nil, tcell.StyleDefault.Dim(true))
+				}
+			}
+		}
+	}
+
+	app.drawText(1, h-2, w-1, app.status, tcell.StyleDefault)
+	if p := app.prompt; p != nil {
+		x := app.drawText(1, h-1, w-1, p.label, tcell.StyleDefault.Bold(true))
+		x += app.drawText(1+x, h-1, w-1-x, string(p.text), tcell.StyleDefault)
+		s.ShowCursor(1+runewidth.StringWidth(p.label)+runewidth.StringWidth(string(p.text[:p.cursor])), h-1)
+	} else {
+		s.HideCursor()
+	}
+	s.Show()
+}
+
+// layout returns the width of each pane and how many panes fit on a screen w columns wide, scrolling the panes
+// sideways if needed to keep the focused one in view.
+func (app *App) layout(w int) (paneWidth, visible int) {
+	paneWidth = w / len(app.panes)
+	if paneWidth < minPaneWidth {
+		paneWidth = minPaneWidth
+	}
+	if paneWidth > w {
+		paneWidth = w
+	}
+	visible = w / paneWidth
+	if visible < 1 {
+		visible = 1
+	}
+
+	if app.focus < app.left {
+		app.left = app.focus
+	}
+	if app.focus >= app.left+visible {
+		app.left = app.focus - visible + 1
+	}
+	if app.left > len(app.panes)-visible {
+		app.left = len(app.panes) - visible
+	}
+	if app.left < 0 {
+		app.left = 0
+	}
+	return paneWidth, visible
+}
+
+// pageSize is the number of items which fit in a pane.
+func (app *App) pageSize() int {
+	_, h := app.screen.Size()
+	if h-4 < 1 {
+		return 1
+	}
+	return h - 4
+}
+
+// drawPane draws a board heading followed by as many of its items as fit in height rows, scrolling to keep the cursor
+// in view.
+func (app *App) drawPane(p *pane, x, y, width, height int, focused bool) {
+	done, tasks := 0, 0
+	for _, it := range p.items {
+		if it.IsTask() && it.State() != data.Cancelled {
+			tasks++
+			if it.IsComplete() {
+				done++
+			}
+		}
+	}
+	heading := styleOf(app.theme.Heading)
+	if focused {
+		heading = heading.Reverse(true)
+		app.fill(x, y, width, heading)
+	}
+	app.drawText(x+1, y, width-1, fmt.Sprintf("%s [%d/%d]", p.board, done, tasks), heading)
+
+	rows := height - 1
+	if rows < 1 {
+		return
+	}
+	if p.cursor < p.top {
+		p.top = p.cursor
+	}
+	if p.cursor >= p.top+rows {
+		p.top = p.cursor - rows + 1
+	}
+	if p.top > len(p.items)-rows {
+		p.top = len(p.items) - rows
+	}
+	if p.top < 0 {
+		p.top = 0
+	}
+
+	if len(p.items) == 0 {
+		app.drawText(x+1, y+1, width-1, "(no items)", tcell.StyleDefault.Dim(true))
+		return
+	}
+	for row := 0; row < rows && p.top+row < len(p.items); row++ {
+		app.drawItem(p.items[p.top+row], x, y+1+row, width, focused && p.top+row == p.cursor)
+	}
+}
+
+// drawItem draws an item on a single row, cutting its description short to fit.
+func (app *App) drawItem(it *data.Item, x, y, width int, selected bool) {
+	base := tcell.StyleDefault
+	if selected {
+		base = base.Reverse(true)
+		app.fill(x, y, width, base)
+	}
+	glyph, glyphStyle := app.theme.Marker(it)
+
+	col := x + 1
+	col += app.drawText(col, y, x+width-col, fmt.Sprintf("%3d. ", it.Id), merge(base, app.theme.Id))
+	col += app.drawText(col, y, x+width-col, glyph+" ", merge(base, glyphStyle))
+	descStyle := base
+	if it.IsStarred() {
+		col += app.drawText(col, y, x+width-col, app.theme.Glyphs.Star, merge(base, app.theme.Starred))
+		if !it.IsComplete() {
+			descStyle = merge(base, app.theme.Starred)
+		}
+	}
+	desc := it.Desc
+	if room := x + width - col; runewidth.StringWidth(desc) > room {
+		desc = runewidth.Truncate(desc, room, "…")
+	}
+	app.drawText(col, y, x+width-col, desc, descStyle)
+}
+
+// drawText draws text from column x, stopping before it would pass width columns, and returns the number of columns
+// used. Wide characters take up two columns.
+func (app *App) drawText(x, y, width int, text string, style tcell.Style) int {
+	used := 0
+	for _, r := range text {
+		rw := runewidth.RuneWidth(r)
+		if rw == 0 {
+			continue
+		}
+		if used+rw > width {
+			break
+		}
+		app.screen.SetContent(x+used, y, r, nil, style)
+		used += rw
+	}
+	return used
+}
+
+// fill paints width columns from x with the background of style.
+func (app *App) fill(x, y, width int, style tcell.Style) {
+	for i := 0; i < width; i++ {
+		app.screen.SetContent(x+i, y, ' ', nil, style)
+	}
+}
+
+// styleOf converts a theme style to the closest tcell style.
+func styleOf(style view.Style) tcell.Style {
+	return merge(tcell.StyleDefault, style)
+}
+
+// merge adds the attributes of a theme style to base.
+func merge(base tcell.Style, style view.Style) tcell.Style {
+	for _, attr := range style {
+		switch {
+		case attr == color.Bold:
+			base = base.Bold(true)
+		case attr == color.Faint:
+			base = base.Dim(true)
+		case attr == color.Italic:
+			base = base.Italic(true)
+		case attr == color.Underline:
+			base = base.Underline(true)
+		case attr == color.ReverseVideo:
+			base = base.Reverse(true)
+		case attr >= color.FgBlack && attr <= color.FgWhite:
+			base = base.Foreground(tcell.Color(attr - color.FgBlack))
+		case attr >= color.FgHiBlack && attr <= color.FgHiWhite:
+			base = base.Foreground(tcell.Color(attr - color.FgHiBlack + 8))
+		}
+	}
+	return base
+}
@@ -0,0 +1,117 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// burndownHeight is the most rows a burndown chart may take up; taller charts are scaled down to fit.
+const burndownHeight = 16
+
+// burndownMargin is the width of the axis labels to the left of a burndown chart.
+const burndownMargin = 8
+
+// PrintBurndown charts the open tasks remaining on a board each day from the day of from to the terminal.
+func PrintBurndown(board string, from time.Time, remaining []int) {
+	NewRenderer(color.Output, nil).PrintBurndown(board, from, remaining)
+}
+
+// PrintBurndown draws one column per day for the tasks left open at the end of that day, together with the ideal line
+// running from the first day's total down to nothing on the last day. When the days do not fit in the width set by
+// SetWidth, each column covers several days and shows the tasks left open at the end of the last of them.
+func (r *Renderer) PrintBurndown(board string, from time.Time, remaining []int) {
+	days := len(remaining)
+	last := from.AddDate(0, 0, days-1)
+	fmt.Fprintf(r.out, "\n  %s burndown, %s to %s", r.theme.Heading.Sprint(board), from.Format("2006-01-02"), last.Format("2006-01-02"))
+	if days == 0 {
+		fmt.Fprint(r.out, "\n\n")
+		return
+	}
+	perColumn := burndownBucket(days, r.width)
+	if perColumn > 1 {
+		fmt.Fprintf(r.out, ", %d days per column", perColumn)
+	}
+	fmt.Fprint(r.out, "\n\n")
+
+	most := 0
+	for _, n := range remaining {
+		if n > most {
+			most = n
+		}
+	}
+	height := most
+	if height > burndownHeight {
+		height = burndownHeight
+	}
+	if height == 0 {
+		height = 1
+	}
+
+	ideal := make([]float64, days)
+	for i := range ideal {
+		if days == 1 {
+			ideal[i] = float64(remaining[0])
+		} else {
+			ideal[i] = float64(remaining[0]) * float64(days-1-i) / float64(days-1)
+		}
+	}
+
+	// Each column shows the last day it covers, so that the final column always shows the last day.
+	var shown []int
+	for i := (days - 1) % perColumn; i < days; i += perColumn {
+		shown = append(shown, i)
+	}
+
+	for row := height; row >= 1; row-- {
+		// Each row covers counts above the row below it, up to and including its own level.
+		level := float64(most) * float64(row) / float64(height)
+		below := float64(most) * float64(row-1) / float64(height)
+
+		label := "    "
+		if row == height || row == (height+1)/2 {
+			label = fmt.Sprintf("%4d", int(level+0.5))
+		}
+		var b strings.Builder
+		for _, i := range shown {
+			n := remaining[i]
+			onIdeal := ideal[i] > below && ideal[i] <= level
+			switch {
+			case float64(n) > below && onIdeal:
+				b.WriteString(r.theme.Pending.Sprint("▒▒") + " ")
+			case float64(n) > below:
+				b.WriteString(r.theme.Done.Sprint("██") + " ")
+			case onIdeal:
+				b.WriteString(r.theme.Pending.Sprint("··") + " ")
+			default:
+				b.WriteString("   ")
+			}
+		}
+		fmt.Fprintf(r.out, "  %s │%s\n", label, strings.TrimRight(b.String(), " "))
+	}
+	fmt.Fprintf(r.out, "  %4d └%s\n", 0, strings.Repeat("───", len(shown)))
+
+	var labels strings.Builder
+	for _, i := range shown {
+		labels.WriteString(from.AddDate(0, 0, i).Format("02") + " ")
+	}
+	fmt.Fprintf(r.out, "        %s\n\n", strings.TrimRight(labels.String(), " "))
+	fmt.Fprintf(r.out, "  %s remaining   %s or %s ideal   %d tasks left, from %d at the start\n",
+		r.theme.Done.Sprint("██"), r.theme.Pending.Sprint("··"), r.theme.Pending.Sprint("▒▒"), remaining[days-1], remaining[0])
+}
+
+// burndownBucket returns how many days each column of a burndown chart must cover for days to fit in width columns of
+// text, given the label to the left of the chart and three columns of text per chart column. A width of zero fits any
+// number of days.
+func burndownBucket(days, width int) int {
+	columns := (width - burndownMargin + 1) / 3
+	if width <= 0 || days <= columns {
+		return 1
+	}
+	if columns < 1 {
+		columns = 1
+	}
+	return (days + columns - 1) / columns
+}
@@ -0,0 +1,181 @@
+package view
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kalexmills/collabbook-go/data"
+)
+
+// Ordering chooses the order of the items within each section. Ties are always broken by display number, so every
+// ordering is deterministic.
+type Ordering string
+
+const (
+	ById       Ordering = "id"       // by display number
+	ByCreated  Ordering = "created"  // oldest first
+	ByPriority Ordering = "priority" // starred items first, then by status
+	ByStatus   Ordering = "status"   // work in progress first, then blocked, to do, notes, done and cancelled
+	ByDesc     Ordering = "desc"     // alphabetically by description, ignoring case
+)
+
+// Grouping chooses how items are split into sections.
+type Grouping string
+
+const (
+	GroupByBoard  Grouping = "board"  // a section for each board, listing items on several boards in each of them
+	GroupByStatus Grouping = "status" // a section for each state a task may be in, and one for notes
+	GroupByDay    Grouping = "day"    // a section for each day on which items were created, oldest first
+	GroupNone     Grouping = "none"   // a single section
+)
+
+// ParseOrdering returns the Ordering with the given name.
+func ParseOrdering(name string) (Ordering, error) {
+	switch order := Ordering(name); order {
+	case ById, ByCreated, ByPriority, ByStatus, ByDesc:
+		return order, nil
+	}
+	return "", fmt.Errorf("unknown sort order %q; expected id, created, priority, status or desc", name)
+}
+
+// ParseGrouping returns the Grouping with the given name.
+func ParseGrouping(name string) (Grouping, error) {
+	switch group := Grouping(name); group {
+	case GroupByBoard, GroupByStatus, GroupByDay, GroupNone:
+		return group, nil
+	}
+	return "", fmt.Errorf("unknown grouping %q; expected board, status, day or none", name)
+}
+
+// statusRank places tasks being worked on first and finished ones last, with notes in between.
+func statusRank(it *data.Item) int {
+	if !it.IsTask() {
+		return 3
+	}
+	switch it.State() {
+	case data.InProgress:
+		return 0
+	case data.Blocked:
+		return 1
+	case data.Done:
+		return 4
+	case data.Cancelled:
+		return 5
+	}
+	return 2
+}
+
+// statusHeading names the section of an item grouped by status.
+func statusHeading(it *data.Item) string {
+	if !it.IsTask() {
+		return "notes"
+	}
+	return it.State().String()
+}
+
+// SortItems sorts items in place by order.
+func SortItems(items []*data.Item, order Ordering) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		switch order {
+		case ByCreated:
+			if !a.CreatedUTC.Equal(b.CreatedUTC) {
+				return a.CreatedUTC.Before(b.CreatedUTC)
+			}
+		case ByPriority:
+			if a.IsStarred() != b.IsStarred() {
+				return a.IsStarred()
+			}
+			if statusRank(a) != statusRank(b) {
+				return statusRank(a) < statusRank(b)
+			}
+		case ByStatus:
+			if statusRank(a) != statusRank(b) {
+				return statusRank(a) < statusRank(b)
+			}
+		case ByDesc:
+			if da, db := strings.ToLower(a.Desc), strings.ToLower(b.Desc); da != db {
+				return da < db
+			}
+		}
+		return a.Id < b.Id
+	})
+}
+
+// SortBoards sorts board names in place, with DefaultBoard first, ArchiveBoard last and the rest in alphabetical
+// order.
+func SortBoards(boards []string) {
+	sort.Slice(boards, func(i, j int) bool { return boardLess(boards[i], boards[j]) })
+}
+
+func boardLess(a, b string) bool {
+	rank := func(board string) int {
+		switch board {
+		case data.DefaultBoard:
+			return 0
+		case data.ArchiveBoard:
+			return 2
+		}
+		return 1
+	}
+	if rank(a) != rank(b) {
+		return rank(a) < rank(b)
+	}
+	return a < b
+}
+
+// GroupedSections returns a section factory for PrintSections which splits the items returned by items into groups,
+// sorting the items within each group by order. Only groups holding at least one item are returned.
+func GroupedSections(store *data.Repo, items func() []*data.Item, by Grouping, order Ordering) func() []Section {
+	return func() []Section {
+		all := items()
+		SortItems(all, order)
+
+		// Each group has a key which sorts the groups, and a heading which is shown.
+		type group struct {
+			key, heading string
+			ids          []uint64
+		}
+		var groups []*group
+		byKey := make(map[string]*group)
+		add := func(key, heading string, id uint64) {
+			g, ok := byKey[key]
+			if !ok {
+				g = &group{key: key, heading: heading}
+				byKey[key] = g
+				groups = append(groups, g)
+			}
+			g.ids = append(g.ids, id)
+		}
+
+		for _, it := range all {
+			switch by {
+			case GroupByBoard:
+				for _, board := range store.BoardsOf(it.Id) {
+					add(board, board, it.Id)
+				}
+			case GroupByStatus:
+				add(strconv.Itoa(statusRank(it)), statusHeading(it), it.Id)
+			case GroupByDay:
+				created := it.CreatedUTC.Local()
+				add(created.Format("2006-01-02"), created.Format("Mon Jan 02 2006"), it.Id)
+			default:
+				add("", "All items", it.Id)
+			}
+		}
+
+		sort.SliceStable(groups, func(i, j int) bool {
+			if by == GroupByBoard {
+				return boardLess(groups[i].key, groups[j].key)
+			}
+			return groups[i].key < groups[j].key
+		})
+		sections := make([]Section, len(groups))
+		for i, g := range groups {
+			sections[i] = Section{Heading: &g.heading, Items: g.ids}
+		}
+		return sections
+	}
+}
@@ -0,0 +1,231 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kalexmills/collabbook-go/data"
+)
+
+// Format selects how a Renderer writes its output.
+type Format string
+
+const (
+	Text   Format = "text"   // colored text for people to read
+	JSON   Format = "json"   // a single JSON document
+	NDJSON Format = "ndjson" // one JSON object per line, one line per item where output lists items
+)
+
+// ParseFormat returns the Format with the given name.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case Text, JSON, NDJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown output format %q; expected text, json or ndjson", name)
+}
+
+// The types below define the JSON written by Renderers. Fields are only ever added to them, never renamed or removed,
+// so that scripts reading the output keep working. Times are RFC 3339 timestamps, and are omitted when unknown.
+
+// jsonItem describes a single item.
+type jsonItem struct {
+	Id          uint64     `json:"id"`
+	Uid         string     `json:"uid"`
+	Type        string     `json:"type"` // "task" or "note"
+	Complete    bool       `json:"complete"`
+	State       string     `json:"state,omitempty"` // of tasks: todo, in-progress, blocked, done or cancelled
+	Starred     bool       `json:"starred"`
+	Description string     `json:"description"`
+	Boards      []string   `json:"boards"`
+	Created     time.Time  `json:"created"`
+	Completed   *time.Time `json:"completed,omitempty"`
+	Reopened    *time.Time `json:"reopened,omitempty"`
+}
+
+// jsonSection is a heading and the items listed beneath it.
+type jsonSection struct {
+	Heading string     `json:"heading"`
+	Items   []jsonItem `json:"items"`
+}
+
+// jsonSummary counts the items listed in every section. Pending counts every open task, as it did before tasks had
+// states, and Todo, InProgress and Blocked break it down.
+type jsonSummary struct {
+	Done       int `json:"done"`
+	Todo       int `json:"todo"`
+	InProgress int `json:"inProgress"`
+	Blocked    int `json:"blocked"`
+	Pending    int `json:"pending"`
+	Cancelled  int `json:"cancelled"`
+	Notes      int `json:"notes"`
+}
+
+// jsonListing is written by PrintSections in JSON format. In NDJSON format, each item is written on its own line
+// instead, as a jsonListedItem.
+type jsonListing struct {
+	Sections []jsonSection `json:"sections"`
+	Summary  jsonSummary   `json:"summary"`
+}
+
+// jsonListedItem is an item written by PrintSections in NDJSON format, together with the heading it was listed under.
+type jsonListedItem struct {
+	Section string `json:"section"`
+	jsonItem
+}
+
+// jsonDetails is written by PrintDetails in JSON format. In NDJSON format, each item is written on its own line.
+type jsonDetails struct {
+	Items []jsonItem `json:"items"`
+}
+
+// jsonStats is written by PrintStats, on a single line in NDJSON format.
+type jsonStats struct {
+	PerDay          []jsonDay           `json:"perDay"`
+	PerWeek         []jsonWeek          `json:"perWeek"`
+	LeadTimeSeconds int64               `json:"medianLeadTimeSeconds"`
+	Measured        int                 `json:"measured"`
+	Boards          []jsonBoardActivity `json:"boards"`
+	Starred         int                 `json:"starred"`
+	Overdue         int                 `json:"overdue"`
+	Oldest          []jsonItem          `json:"oldest"`
+}
+
+type jsonDay struct {
+	Date      string `json:"date"` // in the form 2006-01-02
+	Completed int    `json:"completed"`
+}
+
+type jsonWeek struct {
+	From      string `json:"from"` // first day of the week, in the form 2006-01-02
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+type jsonBoardActivity struct {
+	Name      string `json:"name"`
+	Done      int    `json:"done"`
+	Open      int    `json:"open"`
+	Completed int    `json:"completed"`
+}
+
+func (r *Renderer) jsonItem(it *data.Item) jsonItem {
+	result := jsonItem{
+		Id:          it.Id,
+		Uid:         it.Uid,
+		Type:        "note",
+		Complete:    it.IsComplete(),
+		Starred:     it.IsStarred(),
+		Description: it.Desc,
+		Boards:      []string{},
+		Created:     it.CreatedUTC,
+	}
+	if it.IsTask() {
+		result.Type = "task"
+		result.State = it.State().String()
+	}
+	if r.store != nil {
+		if boards := r.store.BoardsOf(it.Id); boards != nil {
+			result.Boards = boards
+		}
+	}
+	if !it.CompletedUTC.IsZero() {
+		completed := it.CompletedUTC
+		result.Completed = &completed
+	}
+	if !it.ReopenedUTC.IsZero() {
+		reopened := it.ReopenedUTC
+		result.Reopened = &reopened
+	}
+	return result
+}
+
+// writeJSON writes v as indented JSON in JSON format, or on a single line in NDJSON format.
+func (r *Renderer) writeJSON(v interface{}) {
+	enc := json.NewEncoder(r.out)
+	if r.format == JSON {
+		enc.SetIndent("", "  ")
+	}
+	enc.Encode(v)
+}
+
+func (r *Renderer) printSectionsJSON(sections []Section) {
+	listing := jsonListing{Sections: []jsonSection{}}
+	for _, section := range sections {
+		if len(section.Items) == 0 {
+			continue
+		}
+		count := countAll(r.store, section.Items)
+		listing.Summary.Done += count.states[data.Done]
+		listing.Summary.InProgress += count.states[data.InProgress]
+		listing.Summary.Blocked += count.states[data.Blocked]
+		listing.Summary.Todo += count.states[data.Todo]
+		listing.Summary.Pending += count.states[data.Todo] + count.states[data.InProgress] + count.states[data.Blocked]
+		listing.Summary.Cancelled += count.states[data.Cancelled]
+		listing.Summary.Notes += count.notes
+
+		js := jsonSection{Heading: *section.Heading, Items: []jsonItem{}}
+		for _, id := range section.Items {
+			if item := r.store.Item(id); item != nil {
+				js.Items = append(js.Items, r.jsonItem(item))
+			}
+		}
+		listing.Sections = append(listing.Sections, js)
+	}
+
+	if r.format == JSON {
+		r.writeJSON(listing)
+		return
+	}
+	for _, section := range listing.Sections {
+		for _, item := range section.Items {
+			r.writeJSON(jsonListedItem{section.Heading, item})
+		}
+	}
+}
+
+func (r *Renderer) printDetailsJSON(ids []uint64) {
+	details := jsonDetails{Items: []jsonItem{}}
+	for _, id := range ids {
+		if item := r.store.Item(id); item != nil {
+			details.Items = append(details.Items, r.jsonItem(item))
+		}
+	}
+
+	if r.format == JSON {
+		r.writeJSON(details)
+		return
+	}
+	for _, item := range details.Items {
+		r.writeJSON(item)
+	}
+}
+
+func (r *Renderer) printStatsJSON(stats data.Stats) {
+	result := jsonStats{
+		PerDay:          []jsonDay{},
+		PerWeek:         []jsonWeek{},
+		LeadTimeSeconds: int64(stats.LeadTime / time.Second),
+		Measured:        stats.Measured,
+		Boards:          []jsonBoardActivity{},
+		Starred:         stats.Starred,
+		Overdue:         stats.Overdue,
+		Oldest:          []jsonItem{},
+	}
+	for i, n := range stats.PerDay {
+		day := stats.Now.AddDate(0, 0, i-len(stats.PerDay)+1)
+		result.PerDay = append(result.PerDay, jsonDay{day.Format("2006-01-02"), n})
+	}
+	for i, n := range stats.PerWeek {
+		from := stats.Now.AddDate(0, 0, 7*(i-len(stats.PerWeek)+1)-6)
+		result.PerWeek = append(result.PerWeek, jsonWeek{from.Format("2006-01-02"), stats.CreatedPerWeek[i], n})
+	}
+	for _, board := range stats.Boards {
+		result.Boards = append(result.Boards, jsonBoardActivity{board.Name, board.Done, board.Open, board.Completed})
+	}
+	for _, it := range stats.Oldest {
+		result.Oldest = append(result.Oldest, r.jsonItem(it))
+	}
+	r.writeJSON(result)
+}
@@ -0,0 +1,138 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	columnGap      = 3  // columns between kanban columns
+	minColumnWidth = 24 // narrowest a kanban column may be before columns move onto another band
+	kanbanWidth    = 80 // width of kanban boards written anywhere other than a terminal
+)
+
+// cell is a line of a kanban column, together with its width on screen once any colors are removed.
+type cell struct {
+	text  string
+	width int
+}
+
+// PrintColumns renders the sections produced by factory side by side, as the columns of a kanban board, fitted to
+// the width of the renderer. Sections which do not fit beside each other continue below, in further bands of
+// columns. Empty sections are shown as empty columns so that the layout does not shift as items move.
+func (r *Renderer) PrintColumns(factory func() []Section) {
+	sections := factory()
+	if r.format == JSON || r.format == NDJSON {
+		r.printSectionsJSON(sections)
+		return
+	}
+	if len(sections) == 0 {
+		r.hoorayNothingToDo()
+		return
+	}
+
+	width := r.width
+	if width <= 0 {
+		width = kanbanWidth
+	}
+	width -= 2 // the indent of every line
+	perBand := (width + columnGap) / (minColumnWidth + columnGap)
+	if perBand < 1 {
+		perBand = 1
+	}
+	if perBand > len(sections) {
+		perBand = len(sections)
+	}
+	columnWidth := (width - columnGap*(perBand-1)) / perBand
+	if columnWidth < minColumnWidth {
+		columnWidth = minColumnWidth
+	}
+
+	for start := 0; start < len(sections); start += perBand {
+		end := start + perBand
+		if end > len(sections) {
+			end = len(sections)
+		}
+		columns := make([][]cell, 0, end-start)
+		for _, section := range sections[start:end] {
+			columns = append(columns, r.column(section, columnWidth))
+		}
+		r.printBand(columns, columnWidth)
+	}
+	r.printFooter()
+}
+
+// column returns the lines of a single kanban column: its heading, then each of its items wrapped to fit width.
+func (r *Renderer) column(section Section, width int) []cell {
+	count := countAll(r.store, section.Items)
+	r.total.add(count)
+	sDone, sTasks := count.done(), count.tasks()
+
+	heading := fmt.Sprintf("%s [%d/%d]", *section.Heading, sDone, sTasks)
+	styled := r.boardHeading(*section.Heading, sDone, sTasks)
+	if runewidth.StringWidth(heading) > width {
+		heading = runewidth.Truncate(heading, width, ellipsis)
+		styled = r.theme.Heading.Sprint(heading)
+	}
+	result := []cell{{styled, runewidth.StringWidth(heading)}}
+
+	for _, id := range section.Items {
+		it := r.store.Item(id)
+		if it == nil {
+			continue
+		}
+		glyph, glyphStyle := r.theme.Marker(it)
+		prefix := fmt.Sprintf("%d. %s ", it.Id, glyph)
+		idStyle := r.theme.Id
+		if r.changed[it.Id] {
+			idStyle = r.theme.Changed
+		}
+		styled := idStyle.Sprint(fmt.Sprintf("%d.", it.Id)) + " " + glyphStyle.Sprint(glyph) + " "
+		if it.IsStarred() {
+			prefix += r.theme.Glyphs.Star
+			styled += r.theme.Starred.Sprint(r.theme.Glyphs.Star)
+		}
+		indent := runewidth.StringWidth(prefix)
+
+		style := r.theme.descriptionStyle(it)
+		for i, line := range fit(it.Desc, width-indent, r.truncate) {
+			if i == 0 {
+				result = append(result, cell{styled + style.Sprint(line), indent + runewidth.StringWidth(line)})
+			} else {
+				result = append(result, cell{strings.Repeat(" ", indent) + style.Sprint(line),
+					indent + runewidth.StringWidth(line)})
+			}
+		}
+	}
+	return result
+}
+
+// printBand prints columns side by side, padding each line to the width of its column.
+func (r *Renderer) printBand(columns [][]cell, width int) {
+	rows := 0
+	for _, column := range columns {
+		if len(column) > rows {
+			rows = len(column)
+		}
+	}
+	for row := 0; row < rows; row++ {
+		var b strings.Builder
+		b.WriteString("  ")
+		for i, column := range columns {
+			last := i == len(columns)-1
+			if row < len(column) {
+				b.WriteString(column[row].text)
+				// Cells are fitted to the column, but a wide character that could not be split may still overflow it.
+				if pad := width - column[row].width + columnGap; !last && pad > 0 {
+					b.WriteString(strings.Repeat(" ", pad))
+				}
+			} else if !last {
+				b.WriteString(strings.Repeat(" ", width+columnGap))
+			}
+		}
+		fmt.Fprintln(r.out, strings.TrimRight(b.String(), " "))
+	}
+	fmt.Fprintln(r.out)
+}
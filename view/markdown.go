@@ -0,0 +1,102 @@
+package view
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kalexmills/collabbook-go/data"
+)
+
+// PrintMarkdown writes the sections produced by factory as GitHub-flavored Markdown, for pasting into pull requests
+// and wiki pages. Each non-empty section becomes a heading followed by a list, in which tasks are checkboxes, notes
+// are plain bullets and starred items are bold. Cancelled tasks are struck through.
+//
+// With details, each item is followed by its display number, its state when the checkbox does not show it, and when it
+// was created and completed, as in
+//
+//	- [ ] **Fix the build** _(id 3, in-progress, created 2026-10-01)_
+func (r *Renderer) PrintMarkdown(factory func() []Section, details bool) {
+	first := true
+	for _, section := range factory() {
+		if len(section.Items) == 0 {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(r.out)
+		}
+		first = false
+
+		fmt.Fprintf(r.out, "## %s\n\n", markdownEscape(*section.Heading))
+		for _, id := range section.Items {
+			if it := r.store.Item(id); it != nil {
+				fmt.Fprintln(r.out, markdownItem(it, details))
+			}
+		}
+	}
+}
+
+// markdownItem returns the list entry for a single item.
+func markdownItem(it *data.Item, details bool) string {
+	var b strings.Builder
+	b.WriteString("- ")
+	if it.IsTask() {
+		if it.IsComplete() {
+			b.WriteString("[x] ")
+		} else {
+			b.WriteString("[ ] ")
+		}
+	}
+
+	desc := markdownEscape(strings.TrimSpace(it.Desc))
+	if it.IsStarred() {
+		desc = "**" + desc + "**"
+	}
+	if it.IsTask() && it.State() == data.Cancelled {
+		desc = "~~" + desc + "~~"
+	}
+	b.WriteString(desc)
+
+	if details {
+		meta := []string{"id " + strconv.FormatUint(it.Id, 10)}
+		if state := it.State(); it.IsTask() && state != data.Todo && state != data.Done {
+			meta = append(meta, state.String())
+		}
+		meta = append(meta, "created "+it.CreatedUTC.Local().Format("2006-01-02"))
+		if it.IsComplete() && !it.CompletedUTC.IsZero() {
+			meta = append(meta, "completed "+it.CompletedUTC.Local().Format("2006-01-02"))
+		}
+		b.WriteString(" _(" + strings.Join(meta, ", ") + ")_")
+	}
+	return b.String()
+}
+
+// markdownSpecial are the characters which have a meaning in Markdown anywhere within a line.
+const markdownSpecial = "\\`*_~[]<>|&"
+
+// markdownListStart matches text which would begin a heading, a nested list, a numbered list or a thematic break if
+// written at the start of a list entry, capturing the character to escape when it is not the first.
+var markdownListStart = regexp.MustCompile(`^(?:(?:#|\+|\d+([.)]))(?:\s|$)|-)`)
+
+// markdownEscape puts a backslash before every character of text which Markdown would otherwise read as formatting,
+// so that descriptions such as "[ ] looks like a task", and board names such as "#release_notes", come out as they
+// were written.
+func markdownEscape(text string) string {
+	var b strings.Builder
+	if m := markdownListStart.FindStringSubmatchIndex(text); m != nil {
+		at := 0
+		if m[2] >= 0 {
+			at = m[2]
+		}
+		b.WriteString(text[:at] + `\`)
+		text = text[at:]
+	}
+	for _, r := range text {
+		if strings.ContainsRune(markdownSpecial, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
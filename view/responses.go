@@ -12,3 +12,8 @@ func Success(emote string, msg string) {
 func Failure(emote string, msg string) {
 	fmt.Fprintf(color.Output, "\n  %s %s", Red(emote), msg)
 }
+
+// Detail prints a line of supporting information beneath a Success or Failure.
+func Detail(msg string) {
+	fmt.Fprintf(color.Output, "\n      %s", msg)
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/kalexmills/collabbook-go/data"
+	"github.com/mattn/go-runewidth"
+	"io"
 	"strings"
 	"strconv"
-	"os"
+	"text/template"
 )
 
 type Section struct {
@@ -14,75 +16,169 @@ type Section struct {
 	Items   []uint64
 }
 
-var done, notes, tasks int
+// Renderer prints sections of items from a Repo to a writer, keeping the running totals shown in the footer. Each
+// Renderer is independent, so several may be used at once to render different books or outputs.
+type Renderer struct {
+	out      io.Writer
+	store    *data.Repo
+	format   Format
+	template *template.Template
+	theme    Theme
+	width    int
+	truncate bool
+	changed  map[uint64]bool
+
+	total tally
+}
 
-var sections []Section
+func NewRenderer(out io.Writer, store *data.Repo) *Renderer {
+	return &Renderer{out: out, store: store, theme: Themes["default"]}
+}
 
+// PrintSections renders the sections produced by factory from store to the terminal.
 func PrintSections(store *data.Repo, factory func() []Section) {
-	sections = factory()
+	NewRenderer(color.Output, store).PrintSections(factory)
+}
 
-	if len(sections) == 0 {
-		hoorayNothingToDo()
-	}
+// SetChanged highlights the items with the given ids as having changed, e.g. since the output was last rendered.
+func (r *Renderer) SetChanged(ids map[uint64]bool) {
+	r.changed = ids
+}
 
-	printSections(store)
-	printFooter()
+// SetFormat chooses how the renderer writes its output. Renderers write Text unless told otherwise.
+func (r *Renderer) SetFormat(format Format) {
+	r.format = format
 }
 
-func printSections(store *data.Repo) {
-	allDone := true
+func (r *Renderer) PrintSections(factory func() []Section) {
+	if r.format == JSON || r.format == NDJSON {
+		r.printSectionsJSON(factory())
+		return
+	}
+	if !r.printSections(factory()) {
+		r.hoorayNothingToDo()
+		return
+	}
+	r.printFooter()
+}
+
+// printSections prints each non-empty section, returning false if there was nothing to print.
+func (r *Renderer) printSections(sections []Section) bool {
+	printed := false
 	for _, section := range sections {
 		if len(section.Items) > 0 {
-			allDone = false
-			sDone, sNotes, sTasks := countAll(store, section.Items)
-			done += sDone
-			notes += sNotes
-			tasks += sTasks
+			printed = true
+			count := countAll(r.store, section.Items)
+			r.total.add(count)
 
-			printBoardHeading(*section.Heading, sDone, sTasks)
+			r.printBoardHeading(*section.Heading, count.done(), count.tasks())
 			for _, id := range section.Items {
-				if item := store.Item(id); item != nil {
-					printItem(item)
+				item := r.store.Item(id)
+				if item == nil {
+					continue
+				}
+				if r.template != nil {
+					r.printRow(item)
+				} else {
+					r.printItem(item)
 				}
 			}
-			fmt.Println()
+			fmt.Fprintln(r.out)
 		}
 	}
-	if allDone {
-		hoorayNothingToDo()
+	return printed
+}
+
+func (r *Renderer) hoorayNothingToDo() {
+	fmt.Fprintf(r.out, "\n  %s %s", r.theme.Done.Sprint(`\(^_^)/`), "All done!")
+}
+
+// tally counts notes, and tasks in each state.
+type tally struct {
+	notes  int
+	states [data.Cancelled + 1]int
+}
+
+func (t tally) done() int {
+	return t.states[data.Done]
+}
+
+// tasks counts every task which has not been cancelled, so that cancelling a task takes it out of the total rather
+// than leaving it undone forever.
+func (t tally) tasks() int {
+	result := 0
+	for state, n := range t.states {
+		if data.State(state) != data.Cancelled {
+			result += n
+		}
 	}
+	return result
 }
 
-func hoorayNothingToDo() {
-	Success(`\(^_^)/`, "All done!")
-	os.Exit(0)
+func (t *tally) add(other tally) {
+	t.notes += other.notes
+	for state, n := range other.states {
+		t.states[state] += n
+	}
 }
 
-func countAll(store *data.Repo, items []uint64) (done, notes, tasks int) {
+func countAll(store *data.Repo, items []uint64) (result tally) {
 	for _, id := range items {
 		item := store.Item(id)
 		if item == nil {
 			continue
 		}
 		if item.IsTask() {
-			tasks += 1
-			if item.IsComplete() {
-				done += 1
-			}
+			result.states[item.State()] += 1
 		} else {
-			notes += 1
+			result.notes += 1
 		}
 	}
 	return
 }
 
-func printItem(it *data.Item) {
-	star := star(it)
-	fmt.Fprintf(color.Output, "  %4d. %s %s %s %s\n", it.Id, checkbox(it), star, description(it), star)
+// printItem prints an item on a single row, or over several when its description is too wide to fit. Continuation
+// rows are indented to line up with the start of the description.
+func (r *Renderer) printItem(it *data.Item) {
+	id := fmt.Sprintf("%4d.", it.Id)
+	gutter, idStyle := "  ", r.theme.Id
+	if r.changed[it.Id] {
+		gutter, idStyle = r.theme.Changed.Sprint(">")+" ", r.theme.Changed
+	}
+	glyph, glyphStyle := r.theme.Marker(it)
+	star := ""
+	if it.IsStarred() {
+		star = r.theme.Glyphs.Star
+	}
+
+	indent := 2 + runewidth.StringWidth(id) + 1 + runewidth.StringWidth(glyph) + 1 + runewidth.StringWidth(star) + 1
+	width := 0
+	if r.width > 0 {
+		// Leave room for the closing star, and for at least a few characters of description on narrow terminals.
+		width = r.width - indent - 1 - runewidth.StringWidth(star)
+		if width < 10 {
+			width = 10
+		}
+	}
+
+	lines := fit(it.Desc, width, r.truncate)
+	style := r.theme.descriptionStyle(it)
+	star = r.theme.Starred.Sprint(star)
+	for i, line := range lines {
+		if i == 0 {
+			fmt.Fprintf(r.out, "%s%s %s %s %s", gutter, idStyle.Sprint(id), glyphStyle.Sprint(glyph), star, style.Sprint(line))
+		} else {
+			fmt.Fprintf(r.out, "%s%s", strings.Repeat(" ", indent), style.Sprint(line))
+		}
+		if i == len(lines)-1 {
+			fmt.Fprintf(r.out, " %s", star)
+		}
+		fmt.Fprintln(r.out)
+	}
 }
 
-func printFooter() {
-	pending := tasks - done
+func (r *Renderer) printFooter() {
+	done, tasks := r.total.done(), r.total.tasks()
 	var pct int
 	if tasks == 0 {
 		pct = 100
@@ -90,41 +186,68 @@ func printFooter() {
 		pct = (int)((100.0*done)/(1.0*tasks))
 	}
 
-	fmt.Fprintf(color.Output, "  %d%% of all tasks complete.\n", pct)
-	fmt.Fprintln(color.Output, "  "+strings.Join([]string{
-		Green(strconv.Itoa(done)) + " done",
-		Yellow(strconv.Itoa(pending)) + " pending",
-		Blue(strconv.Itoa(notes)) + " notes",
+	fmt.Fprintf(r.out, "  %d%% of all tasks complete.\n", pct)
+	fmt.Fprintln(r.out, "  "+strings.Join([]string{
+		r.theme.Done.Sprint(strconv.Itoa(done)) + " done",
+		r.theme.InProgress.Sprint(strconv.Itoa(r.total.states[data.InProgress])) + " in progress",
+		r.theme.Blocked.Sprint(strconv.Itoa(r.total.states[data.Blocked])) + " blocked",
+		r.theme.Pending.Sprint(strconv.Itoa(r.total.states[data.Todo])) + " pending",
+		r.theme.Cancelled.Sprint(strconv.Itoa(r.total.states[data.Cancelled])) + " cancelled",
+		r.theme.Note.Sprint(strconv.Itoa(r.total.notes)) + " notes",
 	}, " - "))
 }
 
-func printBoardHeading(name string, complete int, total int) {
-	fmt.Fprintf(color.Output, "  %s [%d/%d]\n", White(name), complete, total)
+func (r *Renderer) printBoardHeading(name string, complete int, total int) {
+	fmt.Fprintf(r.out, "  %s\n", r.boardHeading(name, complete, total))
 }
 
-func star(it *data.Item) string {
+func (r *Renderer) boardHeading(name string, complete int, total int) string {
+	return fmt.Sprintf("%s [%d/%d]", r.theme.Heading.Sprint(name), complete, total)
+}
+
+func (t Theme) star(it *data.Item) string {
 	if it.IsStarred() {
-		return Yellow("** ")
+		return t.Starred.Sprint(t.Glyphs.Star)
 	}
 	return ""
 }
 
-func checkbox(it *data.Item) string {
+// Marker returns the glyph printed in place of an item's checkbox, and its style.
+func (t Theme) Marker(it *data.Item) (string, Style) {
 	if !it.IsTask() {
-		return " - "
+		return t.Glyphs.Note, t.Note
 	}
-	if it.IsComplete() {
-		return "[" + Green("X") + "]"
+	switch it.State() {
+	case data.InProgress:
+		return t.Glyphs.InProgress, t.InProgress
+	case data.Blocked:
+		return t.Glyphs.Blocked, t.Blocked
+	case data.Done:
+		return t.Glyphs.Done, t.Done
+	case data.Cancelled:
+		return t.Glyphs.Cancelled, t.Cancelled
 	}
-	return "[ ]"
+	return t.Glyphs.Pending, t.Pending
 }
 
-func description(it *data.Item) string {
+func (t Theme) checkbox(it *data.Item) string {
+	glyph, style := t.Marker(it)
+	return style.Sprint(glyph)
+}
+
+func (t Theme) descriptionStyle(it *data.Item) Style {
+	if it.IsTask() && it.State() == data.Cancelled {
+		return t.Cancelled
+	}
 	if it.IsTask() && it.IsComplete() {
-		return it.Desc
+		return nil
 	}
 	if it.IsStarred() {
-		return Yellow(it.Desc)
+		return t.Starred
 	}
-	return it.Desc
+	return nil
+}
+
+func (t Theme) description(it *data.Item) string {
+	return t.descriptionStyle(it).Sprint(it.Desc)
 }
@@ -0,0 +1,168 @@
+package view
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kalexmills/collabbook-go/data"
+)
+
+// PrintDetails prints everything known about the items with the given ids to the terminal.
+func PrintDetails(store *data.Repo, ids ...uint64) {
+	NewRenderer(color.Output, store).PrintDetails(ids...)
+}
+
+// PrintStats prints a summary of task completion to the terminal.
+func PrintStats(stats data.Stats) {
+	NewRenderer(color.Output, nil).PrintStats(stats)
+}
+
+func (r *Renderer) PrintDetails(ids ...uint64) {
+	if r.format == JSON || r.format == NDJSON {
+		r.printDetailsJSON(ids)
+		return
+	}
+	fmt.Fprintln(r.out)
+	for _, id := range ids {
+		it := r.store.Item(id)
+		if it == nil {
+			continue
+		}
+		r.printItem(it)
+		r.printDetail("id", it.Uid)
+		r.printDetail("boards", strings.Join(r.store.BoardsOf(id), ", "))
+		r.printDetail("created", formatTime(it.CreatedUTC))
+		if !it.CompletedUTC.IsZero() {
+			r.printDetail("completed", formatTime(it.CompletedUTC)+" ("+
+				formatDuration(it.CompletedUTC.Sub(it.CreatedUTC))+" after creation)")
+		}
+		if !it.ReopenedUTC.IsZero() {
+			r.printDetail("reopened", formatTime(it.ReopenedUTC))
+		}
+		fmt.Fprintln(r.out)
+	}
+}
+
+func (r *Renderer) printDetail(name, value string) {
+	fmt.Fprintf(r.out, "        %-10s %s\n", r.theme.Heading.Sprint(name), value)
+}
+
+// sparks are the levels of a sparkline, from lowest to highest.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+func (r *Renderer) PrintStats(stats data.Stats) {
+	if r.format == JSON || r.format == NDJSON {
+		r.printStatsJSON(stats)
+		return
+	}
+	weeks := len(stats.PerWeek)
+
+	fmt.Fprintf(r.out, "\n  %s\n", r.theme.Heading.Sprint("Boards"))
+	width := 0
+	for _, board := range stats.Boards {
+		if len(board.Name) > width {
+			width = len(board.Name)
+		}
+	}
+	for _, board := range stats.Boards {
+		total := board.Done + board.Open
+		if total == 0 {
+			continue
+		}
+		fmt.Fprintf(r.out, "    %-*s  %s %3d/%-3d %3d%%  %s completed in %d weeks\n", width, board.Name,
+			r.bar(board.Done, total, 20), board.Done, total, 100*board.Done/total,
+			r.theme.Done.Sprint(strconv.Itoa(board.Completed)), weeks)
+	}
+
+	fmt.Fprintf(r.out, "\n  %s\n", r.theme.Heading.Sprint(fmt.Sprintf("Created vs. completed over the last %d weeks", weeks)))
+	fmt.Fprintf(r.out, "    created    %s  %s\n", r.theme.Pending.Sprint(sparkline(stats.CreatedPerWeek)), counts(stats.CreatedPerWeek))
+	fmt.Fprintf(r.out, "    completed  %s  %s\n", r.theme.Done.Sprint(sparkline(stats.PerWeek)), counts(stats.PerWeek))
+
+	fmt.Fprintf(r.out, "\n  %s\n", r.theme.Heading.Sprint("Completed per day"))
+	most := 0
+	for _, n := range stats.PerDay {
+		if n > most {
+			most = n
+		}
+	}
+	for i, n := range stats.PerDay {
+		day := stats.Now.AddDate(0, 0, i-len(stats.PerDay)+1)
+		fmt.Fprintf(r.out, "    %s  %s %d\n", day.Format("Mon 01-02"), r.theme.Done.Sprint(strings.Repeat("█", scale(n, most, 20))), n)
+	}
+
+	fmt.Fprintln(r.out)
+	if stats.Measured == 0 {
+		fmt.Fprintf(r.out, "  %s  no completed tasks yet\n", r.theme.Heading.Sprint("Median lead time"))
+	} else {
+		fmt.Fprintf(r.out, "  %s  %s over %d tasks\n", r.theme.Heading.Sprint("Median lead time"),
+			r.theme.InProgress.Sprint(formatDuration(stats.LeadTime)), stats.Measured)
+	}
+	fmt.Fprintf(r.out, "  %s  %s    %s  %s\n", r.theme.Heading.Sprint("Starred"), r.theme.Starred.Sprint(strconv.Itoa(stats.Starred)),
+		r.theme.Heading.Sprint("Overdue"), r.theme.Blocked.Sprint(strconv.Itoa(stats.Overdue)))
+
+	if len(stats.Oldest) > 0 {
+		fmt.Fprintf(r.out, "\n  %s\n", r.theme.Heading.Sprint("Oldest open tasks"))
+		for _, it := range stats.Oldest {
+			fmt.Fprintf(r.out, "  %4d. %s %s  %s\n", it.Id, r.theme.checkbox(it), r.theme.description(it),
+				r.theme.Blocked.Sprint("open "+formatDuration(stats.Now.Sub(it.CreatedUTC))))
+		}
+	}
+}
+
+// bar draws a bar of the given width, filled in proportion to done out of total.
+func (r *Renderer) bar(done, total, width int) string {
+	filled := scale(done, total, width)
+	return r.theme.Done.Sprint(strings.Repeat("█", filled)) + strings.Repeat("░", width-filled)
+}
+
+// sparkline draws one level for each value, scaled so that the largest value reaches the top.
+func sparkline(values []int) string {
+	most := 0
+	for _, n := range values {
+		if n > most {
+			most = n
+		}
+	}
+	var b strings.Builder
+	for _, n := range values {
+		b.WriteRune(sparks[scale(n, most, len(sparks)-1)])
+	}
+	return b.String()
+}
+
+// counts lists values separated by spaces.
+func counts(values []int) string {
+	result := make([]string, len(values))
+	for i, n := range values {
+		result[i] = fmt.Sprintf("%d", n)
+	}
+	return strings.Join(result, " ")
+}
+
+// scale maps n out of most onto 0 to width, rounding to the nearest step.
+func scale(n, most, width int) int {
+	if most == 0 {
+		return 0
+	}
+	return (n*width + most/2) / most
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// formatDuration rounds d to its two most significant units, e.g. "2d 4h" or "35m".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+	return fmt.Sprintf("%dd %dh", int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour))
+}
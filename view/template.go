@@ -0,0 +1,142 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kalexmills/collabbook-go/data"
+)
+
+// Row is the data passed to item templates, one for each item printed.
+type Row struct {
+	Id        uint64
+	Uid       string
+	Type      string // "task" or "note"
+	Task      bool
+	Complete  bool
+	State     string // of tasks: todo, in-progress, blocked, done or cancelled
+	Starred   bool
+	Desc      string
+	Boards    []string
+	Created   time.Time
+	Completed time.Time // zero unless the task has been completed
+	Reopened  time.Time // zero unless the task has been reopened
+
+	it    *data.Item
+	theme Theme
+}
+
+// templateFuncs are the helper functions available to item templates.
+var templateFuncs = template.FuncMap{
+	"join":     strings.Join,
+	"color":    Themes["default"].colorize,
+	"ago":      ago,
+	"date":     date,
+	"truncate": truncate,
+	"checkbox": func(row Row) string { return row.theme.checkbox(row.it) },
+	"star":     func(row Row) string { return row.theme.star(row.it) },
+}
+
+// ParseTemplate reads a template used to print each item in place of the usual row. Templates are executed with a
+// Row and may use these functions besides the built-in ones:
+//
+//	join LIST SEP      joins a list of strings, e.g. {{join .Boards ", "}}
+//	color NAME TEXT    colors text white, red, yellow, green or blue, e.g. {{color "red" .Desc}}, using the theme's
+//	                   style for headings, blocked, pending or done tasks, or notes respectively
+//	ago TIME           how long ago a time was, e.g. "2d 4h ago", or nothing for the zero time
+//	date TIME          a time in the form 2006-01-02 15:04, or nothing for the zero time
+//	truncate N TEXT    text cut to at most N characters, ending in "..." when cut
+//	checkbox ROW       the usual checkbox of the item, e.g. {{checkbox .}}
+//	star ROW           the usual star of the item, when starred
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("item").Funcs(templateFuncs).Parse(text)
+}
+
+// SetTemplate chooses a template, returned by ParseTemplate, used to print each item in text output. A nil template
+// restores the usual rows.
+func (r *Renderer) SetTemplate(tmpl *template.Template) {
+	r.template = tmpl
+}
+
+// printRow prints an item using the renderer's template, ending the line if the template does not.
+func (r *Renderer) printRow(it *data.Item) {
+	// The colors named in templates follow the renderer's theme rather than the default one they were parsed with.
+	r.template.Funcs(template.FuncMap{"color": r.theme.colorize})
+	var b strings.Builder
+	if err := r.template.Execute(&b, r.row(it)); err != nil {
+		fmt.Fprintln(r.out, r.theme.Blocked.Sprint(err.Error()))
+		return
+	}
+	text := b.String()
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	fmt.Fprint(r.out, text)
+}
+
+func (r *Renderer) row(it *data.Item) Row {
+	result := Row{
+		Id:        it.Id,
+		Uid:       it.Uid,
+		Type:      "note",
+		Task:      it.IsTask(),
+		Complete:  it.IsComplete(),
+		Starred:   it.IsStarred(),
+		Desc:      it.Desc,
+		Boards:    r.store.BoardsOf(it.Id),
+		Created:   it.CreatedUTC,
+		Completed: it.CompletedUTC,
+		Reopened:  it.ReopenedUTC,
+		it:        it,
+		theme:     r.theme,
+	}
+	if it.IsTask() {
+		result.Type = "task"
+		result.State = it.State().String()
+	}
+	return result
+}
+
+// colorize styles text in the theme's style for the element which has the named color in the default theme.
+func (t Theme) colorize(name, text string) (string, error) {
+	switch strings.ToLower(name) {
+	case "white":
+		return t.Heading.Sprint(text), nil
+	case "red":
+		return t.Blocked.Sprint(text), nil
+	case "yellow":
+		return t.Pending.Sprint(text), nil
+	case "green":
+		return t.Done.Sprint(text), nil
+	case "blue":
+		return t.Note.Sprint(text), nil
+	}
+	return "", fmt.Errorf("unknown color %q", name)
+}
+
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return formatDuration(time.Since(t)) + " ago"
+}
+
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return formatTime(t)
+}
+
+func truncate(n int, text string) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	if n <= 3 {
+		return string([]rune(text)[:n])
+	}
+	return string([]rune(text)[:n-3]) + "..."
+}
@@ -0,0 +1,199 @@
+package view
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Style is a set of color attributes applied to an element of the output. An empty Style leaves text as it is.
+type Style []color.Attribute
+
+// Sprint returns text in the style.
+func (s Style) Sprint(text string) string {
+	if len(s) == 0 {
+		return text
+	}
+	return color.New(s...).Sprint(text)
+}
+
+// styleAttributes are the names which may be combined with "+" in a style, e.g. "bold+hi-red".
+var styleAttributes = map[string]color.Attribute{
+	"bold":       color.Bold,
+	"faint":      color.Faint,
+	"italic":     color.Italic,
+	"underline":  color.Underline,
+	"reverse":    color.ReverseVideo,
+	"black":      color.FgBlack,
+	"red":        color.FgRed,
+	"green":      color.FgGreen,
+	"yellow":     color.FgYellow,
+	"blue":       color.FgBlue,
+	"magenta":    color.FgMagenta,
+	"cyan":       color.FgCyan,
+	"white":      color.FgWhite,
+	"hi-black":   color.FgHiBlack,
+	"hi-red":     color.FgHiRed,
+	"hi-green":   color.FgHiGreen,
+	"hi-yellow":  color.FgHiYellow,
+	"hi-blue":    color.FgHiBlue,
+	"hi-magenta": color.FgHiMagenta,
+	"hi-cyan":    color.FgHiCyan,
+	"hi-white":   color.FgHiWhite,
+}
+
+// ParseStyle reads a style written as attribute names joined by "+", e.g. "bold+hi-red". The style "none" leaves
+// text as it is.
+func ParseStyle(spec string) (Style, error) {
+	var result Style
+	for _, name := range strings.Split(spec, "+") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "none" || name == "" {
+			continue
+		}
+		attr, ok := styleAttributes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown color or attribute %q", name)
+		}
+		result = append(result, attr)
+	}
+	return result, nil
+}
+
+// Glyphs are the markers printed before and around items.
+type Glyphs struct {
+	Pending    string // the checkbox of a task still to do
+	InProgress string // the checkbox of a task being worked on
+	Blocked    string // the checkbox of a blocked task
+	Done       string // the checkbox of a completed task
+	Cancelled  string // the checkbox of a cancelled task
+	Note       string // printed in place of a checkbox for notes
+	Star       string // printed around starred items
+}
+
+// Theme decides how each element of a listing is colored and which glyphs mark items.
+type Theme struct {
+	Heading    Style // board and section headings
+	Id         Style // display numbers
+	Done       Style // completed tasks and their count
+	InProgress Style // tasks being worked on and their count
+	Blocked    Style // blocked tasks and their count
+	Pending    Style // tasks still to do and their count
+	Cancelled  Style // cancelled tasks and their count
+	Note       Style // notes and their count
+	Starred    Style // descriptions and stars of starred items
+	Changed    Style // display numbers of items highlighted as changed
+	Glyphs     Glyphs
+}
+
+var asciiGlyphs = Glyphs{Pending: "[ ]", InProgress: "[~]", Blocked: "[!]", Done: "[X]", Cancelled: "[-]", Note: " - ",
+	Star: "** "}
+
+// Themes are the built-in themes, by name.
+var Themes = map[string]Theme{
+	"default": {
+		Heading:    Style{color.FgWhite},
+		Done:       Style{color.FgGreen},
+		InProgress: Style{color.FgCyan},
+		Blocked:    Style{color.FgRed},
+		Pending:    Style{color.FgYellow},
+		Cancelled:  Style{color.FgHiBlack},
+		Note:       Style{color.FgBlue},
+		Starred:    Style{color.FgYellow},
+		Changed:    Style{color.Bold, color.ReverseVideo},
+		Glyphs:     asciiGlyphs,
+	},
+	"mono": {
+		Changed: Style{color.ReverseVideo},
+		Glyphs:  asciiGlyphs,
+	},
+	"high-contrast": {
+		Heading:    Style{color.Bold, color.Underline, color.FgHiWhite},
+		Id:         Style{color.Bold},
+		Done:       Style{color.Bold, color.FgHiGreen},
+		InProgress: Style{color.Bold, color.FgHiBlue},
+		Blocked:    Style{color.Bold, color.FgHiRed},
+		Pending:    Style{color.Bold, color.FgHiYellow},
+		Cancelled:  Style{color.Faint},
+		Note:       Style{color.Bold, color.FgHiCyan},
+		Starred:    Style{color.Bold, color.FgHiMagenta},
+		Changed:    Style{color.Bold, color.ReverseVideo, color.FgHiYellow},
+		Glyphs: Glyphs{Pending: "[ ]", InProgress: "[»]", Blocked: "[!]", Done: "[✔]", Cancelled: "[✘]", Note: " • ",
+			Star: "★ "},
+	},
+}
+
+// ThemeNames lists the names of the built-in themes in alphabetical order.
+func ThemeNames() []string {
+	result := make([]string, 0, len(Themes))
+	for name := range Themes {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// SetStyle sets the style of the named element: heading, id, done, in-progress, blocked, pending, cancelled, note or
+// starred, or changed.
+func (t *Theme) SetStyle(element string, style Style) error {
+	switch strings.ToLower(element) {
+	case "heading":
+		t.Heading = style
+	case "id":
+		t.Id = style
+	case "done":
+		t.Done = style
+	case "in-progress":
+		t.InProgress = style
+	case "blocked":
+		t.Blocked = style
+	case "pending":
+		t.Pending = style
+	case "cancelled":
+		t.Cancelled = style
+	case "note":
+		t.Note = style
+	case "starred":
+		t.Starred = style
+	case "changed":
+		t.Changed = style
+	default:
+		return fmt.Errorf("unknown theme element %q", element)
+	}
+	return nil
+}
+
+// SetGlyph sets the named glyph: pending, in-progress, blocked, done, cancelled, note or star.
+func (t *Theme) SetGlyph(name, glyph string) error {
+	switch strings.ToLower(name) {
+	case "pending":
+		t.Glyphs.Pending = glyph
+	case "in-progress":
+		t.Glyphs.InProgress = glyph
+	case "blocked":
+		t.Glyphs.Blocked = glyph
+	case "done":
+		t.Glyphs.Done = glyph
+	case "cancelled":
+		t.Glyphs.Cancelled = glyph
+	case "note":
+		t.Glyphs.Note = glyph
+	case "star":
+		t.Glyphs.Star = glyph
+	default:
+		return fmt.Errorf("unknown glyph %q", name)
+	}
+	return nil
+}
+
+// SetTheme chooses the theme used for text output. Renderers use the default theme unless told otherwise.
+func (r *Renderer) SetTheme(theme Theme) {
+	r.theme = theme
+}
+
+// Theme returns the theme used for text output.
+func (r *Renderer) Theme() Theme {
+	return r.theme
+}
@@ -0,0 +1,54 @@
+package view
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kalexmills/collabbook-go/data"
+)
+
+// TestMonoTheme checks that nothing printed with the mono theme is colored, even when colors are turned on.
+func TestMonoTheme(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+
+	store := data.NewRepo()
+	done := store.MakeTask("done", "#board")
+	store.ToggleTaskIsComplete(done.Id)
+	store.ToggleItemIsStarred(done.Id)
+	store.MakeTask("open", "#board")
+	store.MakeNote("note", "#board")
+	now := time.Now()
+	tmpl, err := ParseTemplate(`{{checkbox .}} {{star .}}{{color "red" .Desc}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	render := func(theme Theme) string {
+		var out bytes.Buffer
+		r := NewRenderer(&out, store)
+		r.SetTheme(theme)
+		r.SetWidth(80, false)
+		heading := "#board"
+		sections := func() []Section { return []Section{{Heading: &heading, Items: store.IdsInBoard("#board")}} }
+		r.PrintSections(sections)
+		r.PrintSections(func() []Section { return nil })
+		r.PrintColumns(sections)
+		r.PrintDetails(done.Id)
+		r.PrintStats(store.Stats(now, data.StatsOptions{Days: 7, Weeks: 4, Oldest: 3}))
+		r.PrintBurndown(heading, now.AddDate(0, 0, -2), []int{3, 2, 1})
+		r.SetTemplate(tmpl)
+		r.PrintSections(sections)
+		return out.String()
+	}
+
+	if out := render(Themes["default"]); !strings.Contains(out, "\x1b[") {
+		t.Fatalf("the default theme printed no colors, so the mono theme cannot be told apart:\n%s", out)
+	}
+	if out := render(Themes["mono"]); strings.Contains(out, "\x1b[") {
+		t.Errorf("the mono theme printed colors:\n%q", out)
+	}
+}
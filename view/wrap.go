@@ -0,0 +1,59 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ellipsis ends descriptions which were cut short to fit the width of the output.
+const ellipsis = "…"
+
+// SetWidth sets the number of columns that text output should fit in. Long descriptions are wrapped onto further
+// lines, indented to line up beneath the first, or cut short when truncate is true. A width of zero leaves
+// descriptions on a single line however long they are.
+func (r *Renderer) SetWidth(width int, truncate bool) {
+	r.width = width
+	r.truncate = truncate
+}
+
+// fit breaks text into lines of at most width columns, counting wide characters as two columns. Lines are broken at
+// spaces where possible, and within words only when a word is wider than a whole line. When truncate is true, only the
+// first line is kept, ending in an ellipsis if anything was cut.
+func fit(text string, width int, truncate bool) []string {
+	if width <= 0 || runewidth.StringWidth(text) <= width {
+		return []string{text}
+	}
+	if truncate {
+		return []string{runewidth.Truncate(text, width, ellipsis)}
+	}
+
+	var lines []string
+	line, lineWidth := "", 0
+	for _, word := range strings.Fields(text) {
+		wordWidth := runewidth.StringWidth(word)
+		switch {
+		case lineWidth > 0 && lineWidth+1+wordWidth <= width:
+			line, lineWidth = line+" "+word, lineWidth+1+wordWidth
+			continue
+		case lineWidth > 0:
+			lines = append(lines, line)
+			line, lineWidth = "", 0
+		}
+		for wordWidth > width {
+			head := runewidth.Truncate(word, width, "")
+			if head == "" {
+				// A single character wider than the line still has to go somewhere.
+				head = string([]rune(word)[:1])
+			}
+			lines = append(lines, head)
+			word = word[len(head):]
+			wordWidth = runewidth.StringWidth(word)
+		}
+		line, lineWidth = word, wordWidth
+	}
+	if lineWidth > 0 || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}